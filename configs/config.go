@@ -0,0 +1,984 @@
+package configs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/ratelimit"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete runtime configuration. It is assembled by Load from, in increasing order of precedence,
+// defaults, an optional YAML or TOML file, environment variables and command line flags.
+type Config struct {
+	// Storage selects the repositories: mongo, postgres, sqlite for a single local file, or memory to run without a
+	// database.
+	Storage   string
+	HTTP      HTTPConfig
+	TLS       TLSConfig
+	CORS      CORSConfig
+	Security  SecurityConfig
+	Mongo     MongoConfig
+	Postgres  PostgresConfig
+	SQLite    SQLiteConfig
+	Health    HealthConfig
+	Trash     TrashConfig
+	Admin     AdminConfig
+	Log       LogConfig
+	Tracing   TracingConfig
+	RateLimit RateLimitConfig
+	Features  FeatureConfig
+}
+
+type HTTPConfig struct {
+	Addr string
+	// DrainDelay is how long the API keeps serving after it starts reporting itself as not ready, so that load
+	// balancers notice before connections are closed.
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests and background workers are given to finish on shutdown,
+	// after the drain delay.
+	ShutdownTimeout time.Duration
+	// TrustedProxies are the addresses or CIDRs of proxies whose X-Forwarded-For header is believed when working out
+	// a client's address. Nothing is trusted by default.
+	TrustedProxies []string
+	// H2C serves HTTP/2 over plaintext connections for internal callers.
+	H2C bool
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile enable TLS when both are set. They are reloaded when they change on disk.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS, rejecting clients without a certificate signed by this CA.
+	ClientCAFile   string
+	ReloadInterval time.Duration
+	// MinVersion is 1.2 or 1.3.
+	MinVersion string
+}
+
+type CORSConfig struct {
+	// AllowedOrigins are the browser origins allowed to call the API; CORS is disabled when empty.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type SecurityConfig struct {
+	// HSTSMaxAge is sent in Strict-Transport-Security on HTTPS requests; zero disables the header.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	ContentSecurityPolicy string
+	// FrameOptions is DENY, SAMEORIGIN or empty to omit the header.
+	FrameOptions string
+}
+
+type MongoConfig struct {
+	URI            string
+	Database       string
+	ConnectTimeout time.Duration
+	// MigrateOnStart applies pending migrations when the application starts. Disable it to run them with the migrate
+	// command instead.
+	MigrateOnStart bool
+}
+
+type PostgresConfig struct {
+	// DSN is a postgres:// URL or a key=value connection string.
+	DSN            string
+	ConnectTimeout time.Duration
+}
+
+type SQLiteConfig struct {
+	// Path is the database file, created on first start.
+	Path string
+}
+
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check run by the readiness endpoints.
+	CheckTimeout time.Duration
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted habits can be restored before they are purged; zero keeps them forever.
+	Retention time.Duration
+	// PurgeInterval is how often habits past their retention are purged.
+	PurgeInterval time.Duration
+}
+
+type AdminConfig struct {
+	// Token is the bearer token for admin only endpoints, which are disabled when it is empty.
+	Token string
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp.
+	Exporter string
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// SampleRatio is the fraction of new traces recorded, between 0 and 1.
+	SampleRatio float64
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Backend is memory, for limits per instance, or mongo, for limits shared by every instance.
+	Backend string
+	// Default applies to routes without a policy of their own. A zero Limit leaves them unlimited.
+	Default ratelimit.Policy
+	// Routes are keyed by method and unversioned route template, e.g. "POST /user".
+	Routes map[string]ratelimit.Policy
+	// APIKeys are the X-API-Key values clients may be limited by; other keys are ignored.
+	APIKeys []string
+	// UserAPIKeys are the X-API-Key values issued to each user, by user id, that users may be limited by.
+	UserAPIKeys map[string][]string
+}
+
+type FeatureConfig struct {
+	// LegacyRoutes keeps the deprecated unversioned routes mounted alongside /v1.
+	LegacyRoutes       bool
+	LegacyRoutesSunset time.Time
+	// Docs serves the OpenAPI document and Swagger UI.
+	Docs bool
+	// Metrics serves Prometheus metrics at /metrics.
+	Metrics bool
+}
+
+func Default() Config {
+	return Config{
+		Storage: "mongo",
+		HTTP: HTTPConfig{
+			Addr:            "localhost:8080",
+			ShutdownTimeout: 15 * time.Second,
+		},
+		TLS: TLSConfig{
+			ReloadInterval: 30 * time.Second,
+			MinVersion:     "1.2",
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-API-Key", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"Deprecation", "ETag", "Link", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+				"Retry-After", "Sunset", "X-Request-ID",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			FrameOptions:          "DENY",
+		},
+		Mongo: MongoConfig{
+			URI:            "mongodb://localhost:27017",
+			Database:       "golangAPI",
+			ConnectTimeout: 10 * time.Second,
+			MigrateOnStart: true,
+		},
+		Postgres: PostgresConfig{
+			ConnectTimeout: 10 * time.Second,
+		},
+		SQLite: SQLiteConfig{
+			Path: "cadence.db",
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			SampleRatio:  1,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Backend: "memory",
+			Default: ratelimit.Policy{
+				Limit:  300,
+				Period: time.Minute,
+				Keys:   []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP},
+			},
+			Routes: map[string]ratelimit.Policy{
+				"POST /user": {
+					Limit:  10,
+					Period: time.Minute,
+					Keys:   []ratelimit.KeyKind{ratelimit.ByIP},
+				},
+				"GET /user/:email": {
+					Limit:  60,
+					Period: time.Minute,
+					Keys:   []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP},
+				},
+			},
+		},
+		Features: FeatureConfig{
+			LegacyRoutes:       true,
+			LegacyRoutesSunset: time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+			Docs:               true,
+			Metrics:            true,
+		},
+	}
+}
+
+// setting binds one configuration value to its file key, environment variable and flag. Every source is parsed
+// from a string so that all of them share the same validation.
+type setting struct {
+	key   string
+	env   []string
+	usage string
+	get   func(c *Config) string
+	set   func(c *Config, raw string) error
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ReplaceAll(s.key, ".", "-"), "_", "-")
+}
+
+var settings = []setting{
+	{
+		key:   "storage",
+		env:   []string{"CADENCE_STORAGE"},
+		usage: "storage backend: mongo, postgres, sqlite, or memory to keep everything in process",
+		get:   func(c *Config) string { return c.Storage },
+		set:   func(c *Config, raw string) error { c.Storage = strings.ToLower(raw); return nil },
+	},
+	{
+		key:   "http.addr",
+		env:   []string{"CADENCE_HTTP_ADDR"},
+		usage: "address the HTTP server listens on",
+		get:   func(c *Config) string { return c.HTTP.Addr },
+		set:   func(c *Config, raw string) error { c.HTTP.Addr = raw; return nil },
+	},
+	{
+		key:   "http.drain_delay",
+		env:   []string{"CADENCE_HTTP_DRAIN_DELAY"},
+		usage: "time to keep serving after reporting not ready on shutdown",
+		get:   func(c *Config) string { return c.HTTP.DrainDelay.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.HTTP.DrainDelay }),
+	},
+	{
+		key:   "http.shutdown_timeout",
+		env:   []string{"CADENCE_HTTP_SHUTDOWN_TIMEOUT"},
+		usage: "time allowed to drain requests and stop workers on shutdown",
+		get:   func(c *Config) string { return c.HTTP.ShutdownTimeout.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.HTTP.ShutdownTimeout }),
+	},
+	{
+		key:   "http.trusted_proxies",
+		env:   []string{"CADENCE_HTTP_TRUSTED_PROXIES"},
+		usage: "comma separated proxy addresses or CIDRs trusted to set X-Forwarded-For",
+		get:   func(c *Config) string { return strings.Join(c.HTTP.TrustedProxies, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.HTTP.TrustedProxies }),
+	},
+	{
+		key:   "http.h2c",
+		env:   []string{"CADENCE_HTTP_H2C"},
+		usage: "serve HTTP/2 over plaintext connections",
+		get:   func(c *Config) string { return strconv.FormatBool(c.HTTP.H2C) },
+		set:   boolSetter(func(c *Config) *bool { return &c.HTTP.H2C }),
+	},
+	{
+		key:   "tls.cert_file",
+		env:   []string{"CADENCE_TLS_CERT_FILE"},
+		usage: "PEM certificate chain, enables TLS together with tls.key_file",
+		get:   func(c *Config) string { return c.TLS.CertFile },
+		set:   func(c *Config, raw string) error { c.TLS.CertFile = raw; return nil },
+	},
+	{
+		key:   "tls.key_file",
+		env:   []string{"CADENCE_TLS_KEY_FILE"},
+		usage: "PEM private key for tls.cert_file",
+		get:   func(c *Config) string { return c.TLS.KeyFile },
+		set:   func(c *Config, raw string) error { c.TLS.KeyFile = raw; return nil },
+	},
+	{
+		key:   "tls.client_ca_file",
+		env:   []string{"CADENCE_TLS_CLIENT_CA_FILE"},
+		usage: "PEM CA bundle that enables mutual TLS, only accepting clients it signed",
+		get:   func(c *Config) string { return c.TLS.ClientCAFile },
+		set:   func(c *Config, raw string) error { c.TLS.ClientCAFile = raw; return nil },
+	},
+	{
+		key:   "tls.reload_interval",
+		env:   []string{"CADENCE_TLS_RELOAD_INTERVAL"},
+		usage: "how often the certificate files are checked for changes",
+		get:   func(c *Config) string { return c.TLS.ReloadInterval.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.TLS.ReloadInterval }),
+	},
+	{
+		key:   "tls.min_version",
+		env:   []string{"CADENCE_TLS_MIN_VERSION"},
+		usage: "minimum TLS version: 1.2 or 1.3",
+		get:   func(c *Config) string { return c.TLS.MinVersion },
+		set:   func(c *Config, raw string) error { c.TLS.MinVersion = raw; return nil },
+	},
+	{
+		key:   "cors.allowed_origins",
+		env:   []string{"CADENCE_CORS_ALLOWED_ORIGINS"},
+		usage: "comma separated origins allowed to call the API from a browser, e.g. https://*.example.com",
+		get:   func(c *Config) string { return strings.Join(c.CORS.AllowedOrigins, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.CORS.AllowedOrigins }),
+	},
+	{
+		key:   "cors.allowed_methods",
+		env:   []string{"CADENCE_CORS_ALLOWED_METHODS"},
+		usage: "comma separated methods allowed in cross-origin requests",
+		get:   func(c *Config) string { return strings.Join(c.CORS.AllowedMethods, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.CORS.AllowedMethods }),
+	},
+	{
+		key:   "cors.allowed_headers",
+		env:   []string{"CADENCE_CORS_ALLOWED_HEADERS"},
+		usage: "comma separated request headers allowed in cross-origin requests",
+		get:   func(c *Config) string { return strings.Join(c.CORS.AllowedHeaders, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.CORS.AllowedHeaders }),
+	},
+	{
+		key:   "cors.exposed_headers",
+		env:   []string{"CADENCE_CORS_EXPOSED_HEADERS"},
+		usage: "comma separated response headers readable by cross-origin clients",
+		get:   func(c *Config) string { return strings.Join(c.CORS.ExposedHeaders, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.CORS.ExposedHeaders }),
+	},
+	{
+		key:   "cors.allow_credentials",
+		env:   []string{"CADENCE_CORS_ALLOW_CREDENTIALS"},
+		usage: "allow cross-origin requests to send cookies and authorization headers",
+		get:   func(c *Config) string { return strconv.FormatBool(c.CORS.AllowCredentials) },
+		set:   boolSetter(func(c *Config) *bool { return &c.CORS.AllowCredentials }),
+	},
+	{
+		key:   "cors.max_age",
+		env:   []string{"CADENCE_CORS_MAX_AGE"},
+		usage: "how long browsers may cache preflight responses",
+		get:   func(c *Config) string { return c.CORS.MaxAge.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.CORS.MaxAge }),
+	},
+	{
+		key:   "security.hsts_max_age",
+		env:   []string{"CADENCE_SECURITY_HSTS_MAX_AGE"},
+		usage: "Strict-Transport-Security max age for HTTPS requests, 0s to disable",
+		get:   func(c *Config) string { return c.Security.HSTSMaxAge.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Security.HSTSMaxAge }),
+	},
+	{
+		key:   "security.hsts_include_subdomains",
+		env:   []string{"CADENCE_SECURITY_HSTS_INCLUDE_SUBDOMAINS"},
+		usage: "apply Strict-Transport-Security to subdomains",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Security.HSTSIncludeSubdomains) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Security.HSTSIncludeSubdomains }),
+	},
+	{
+		key:   "security.content_security_policy",
+		env:   []string{"CADENCE_SECURITY_CONTENT_SECURITY_POLICY"},
+		usage: "Content-Security-Policy header, empty to omit",
+		get:   func(c *Config) string { return c.Security.ContentSecurityPolicy },
+		set:   func(c *Config, raw string) error { c.Security.ContentSecurityPolicy = raw; return nil },
+	},
+	{
+		key:   "security.frame_options",
+		env:   []string{"CADENCE_SECURITY_FRAME_OPTIONS"},
+		usage: "X-Frame-Options header: DENY, SAMEORIGIN or empty to omit",
+		get:   func(c *Config) string { return c.Security.FrameOptions },
+		set:   func(c *Config, raw string) error { c.Security.FrameOptions = strings.ToUpper(raw); return nil },
+	},
+	{
+		key: "mongo.uri",
+		// MONGOURI is read for compatibility with existing .env files
+		env:   []string{"CADENCE_MONGO_URI", "MONGOURI"},
+		usage: "mongo connection string",
+		get:   func(c *Config) string { return c.Mongo.URI },
+		set:   func(c *Config, raw string) error { c.Mongo.URI = raw; return nil },
+	},
+	{
+		key:   "mongo.database",
+		env:   []string{"CADENCE_MONGO_DATABASE"},
+		usage: "mongo database name",
+		get:   func(c *Config) string { return c.Mongo.Database },
+		set:   func(c *Config, raw string) error { c.Mongo.Database = raw; return nil },
+	},
+	{
+		key:   "mongo.connect_timeout",
+		env:   []string{"CADENCE_MONGO_CONNECT_TIMEOUT"},
+		usage: "time allowed to connect to mongo, e.g. 10s",
+		get:   func(c *Config) string { return c.Mongo.ConnectTimeout.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Mongo.ConnectTimeout }),
+	},
+	{
+		key:   "mongo.migrate_on_start",
+		env:   []string{"CADENCE_MONGO_MIGRATE_ON_START"},
+		usage: "apply pending mongo migrations on startup",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Mongo.MigrateOnStart) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Mongo.MigrateOnStart }),
+	},
+	{
+		key:   "postgres.dsn",
+		env:   []string{"CADENCE_POSTGRES_DSN"},
+		usage: "postgres connection string, used when storage is postgres",
+		get:   func(c *Config) string { return c.Postgres.DSN },
+		set:   func(c *Config, raw string) error { c.Postgres.DSN = raw; return nil },
+	},
+	{
+		key:   "postgres.connect_timeout",
+		env:   []string{"CADENCE_POSTGRES_CONNECT_TIMEOUT"},
+		usage: "time allowed to connect to postgres, e.g. 10s",
+		get:   func(c *Config) string { return c.Postgres.ConnectTimeout.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Postgres.ConnectTimeout }),
+	},
+	{
+		key:   "sqlite.path",
+		env:   []string{"CADENCE_SQLITE_PATH"},
+		usage: "sqlite database file, used when storage is sqlite",
+		get:   func(c *Config) string { return c.SQLite.Path },
+		set:   func(c *Config, raw string) error { c.SQLite.Path = raw; return nil },
+	},
+	{
+		key:   "health.check_timeout",
+		env:   []string{"CADENCE_HEALTH_CHECK_TIMEOUT"},
+		usage: "time allowed for each dependency health check",
+		get:   func(c *Config) string { return c.Health.CheckTimeout.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Health.CheckTimeout }),
+	},
+	{
+		key:   "trash.retention",
+		env:   []string{"CADENCE_TRASH_RETENTION"},
+		usage: "time deleted habits can be restored before they are purged, e.g. 720h; 0 keeps them forever",
+		get:   func(c *Config) string { return c.Trash.Retention.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Trash.Retention }),
+	},
+	{
+		key:   "trash.purge_interval",
+		env:   []string{"CADENCE_TRASH_PURGE_INTERVAL"},
+		usage: "how often deleted habits past their retention are purged",
+		get:   func(c *Config) string { return c.Trash.PurgeInterval.String() },
+		set:   durationSetter(func(c *Config) *time.Duration { return &c.Trash.PurgeInterval }),
+	},
+	{
+		key:   "admin.token",
+		env:   []string{"CADENCE_ADMIN_TOKEN"},
+		usage: "bearer token for admin endpoints, disabled when empty",
+		get:   func(c *Config) string { return c.Admin.Token },
+		set:   func(c *Config, raw string) error { c.Admin.Token = raw; return nil },
+	},
+	{
+		key:   "log.level",
+		env:   []string{"CADENCE_LOG_LEVEL"},
+		usage: "minimum log level: debug, info, warn or error",
+		get:   func(c *Config) string { return c.Log.Level },
+		set:   func(c *Config, raw string) error { c.Log.Level = strings.ToLower(raw); return nil },
+	},
+	{
+		key:   "log.format",
+		env:   []string{"CADENCE_LOG_FORMAT"},
+		usage: "log format: json or text",
+		get:   func(c *Config) string { return c.Log.Format },
+		set:   func(c *Config, raw string) error { c.Log.Format = strings.ToLower(raw); return nil },
+	},
+	{
+		key:   "tracing.exporter",
+		env:   []string{"CADENCE_TRACING_EXPORTER"},
+		usage: "trace exporter: none, stdout or otlp",
+		get:   func(c *Config) string { return c.Tracing.Exporter },
+		set:   func(c *Config, raw string) error { c.Tracing.Exporter = strings.ToLower(raw); return nil },
+	},
+	{
+		key:   "tracing.otlp_endpoint",
+		env:   []string{"CADENCE_TRACING_OTLP_ENDPOINT"},
+		usage: "host:port of the OTLP/HTTP trace collector",
+		get:   func(c *Config) string { return c.Tracing.OTLPEndpoint },
+		set:   func(c *Config, raw string) error { c.Tracing.OTLPEndpoint = raw; return nil },
+	},
+	{
+		key:   "tracing.otlp_insecure",
+		env:   []string{"CADENCE_TRACING_OTLP_INSECURE"},
+		usage: "send traces to the collector over plain HTTP",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Tracing.OTLPInsecure) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Tracing.OTLPInsecure }),
+	},
+	{
+		key:   "tracing.sample_ratio",
+		env:   []string{"CADENCE_TRACING_SAMPLE_RATIO"},
+		usage: "fraction of new traces to record, between 0 and 1",
+		get:   func(c *Config) string { return strconv.FormatFloat(c.Tracing.SampleRatio, 'g', -1, 64) },
+		set: func(c *Config, raw string) error {
+			ratio, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("expected a number, got %q", raw)
+			}
+			c.Tracing.SampleRatio = ratio
+			return nil
+		},
+	},
+	{
+		key:   "ratelimit.enabled",
+		env:   []string{"CADENCE_RATELIMIT_ENABLED"},
+		usage: "limit request rates per client",
+		get:   func(c *Config) string { return strconv.FormatBool(c.RateLimit.Enabled) },
+		set:   boolSetter(func(c *Config) *bool { return &c.RateLimit.Enabled }),
+	},
+	{
+		key:   "ratelimit.backend",
+		env:   []string{"CADENCE_RATELIMIT_BACKEND"},
+		usage: "where rate limit buckets are kept: memory or mongo",
+		get:   func(c *Config) string { return c.RateLimit.Backend },
+		set:   func(c *Config, raw string) error { c.RateLimit.Backend = strings.ToLower(raw); return nil },
+	},
+	{
+		key:   "ratelimit.default",
+		env:   []string{"CADENCE_RATELIMIT_DEFAULT"},
+		usage: "policy for routes without their own, e.g. 300/1m per api_key,ip, or none",
+		get: func(c *Config) string {
+			if c.RateLimit.Default.Limit == 0 {
+				return "none"
+			}
+			return c.RateLimit.Default.String()
+		},
+		set: func(c *Config, raw string) error {
+			if raw == "" || strings.EqualFold(raw, "none") {
+				c.RateLimit.Default = ratelimit.Policy{}
+				return nil
+			}
+			policy, err := ratelimit.ParsePolicy(raw)
+			c.RateLimit.Default = policy
+			return err
+		},
+	},
+	{
+		key:   "ratelimit.routes",
+		env:   []string{"CADENCE_RATELIMIT_ROUTES"},
+		usage: "per route policies, e.g. POST /user = 10/1m per ip; GET /user/:email = 60/1m",
+		get:   func(c *Config) string { return ratelimit.FormatRoutes(c.RateLimit.Routes) },
+		set: func(c *Config, raw string) error {
+			routes, err := ratelimit.ParseRoutes(raw)
+			c.RateLimit.Routes = routes
+			return err
+		},
+	},
+	{
+		key:   "ratelimit.api_keys",
+		env:   []string{"CADENCE_RATELIMIT_API_KEYS"},
+		usage: "comma separated API keys that clients are limited by when sent as X-API-Key, instead of by address",
+		get:   func(c *Config) string { return strings.Join(c.RateLimit.APIKeys, ",") },
+		set:   listSetter(func(c *Config) *[]string { return &c.RateLimit.APIKeys }),
+	},
+	{
+		key:   "ratelimit.user_api_keys",
+		env:   []string{"CADENCE_RATELIMIT_USER_API_KEYS"},
+		usage: "API keys issued to users, which limit them per user, e.g. <user id> = key-1,key-2; <user id> = key-3",
+		get:   func(c *Config) string { return ratelimit.FormatUserAPIKeys(c.RateLimit.UserAPIKeys) },
+		set: func(c *Config, raw string) error {
+			users, err := ratelimit.ParseUserAPIKeys(raw)
+			c.RateLimit.UserAPIKeys = users
+			return err
+		},
+	},
+	{
+		key:   "features.legacy_routes",
+		env:   []string{"CADENCE_FEATURES_LEGACY_ROUTES"},
+		usage: "serve the deprecated unversioned routes",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Features.LegacyRoutes) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Features.LegacyRoutes }),
+	},
+	{
+		key:   "features.legacy_routes_sunset",
+		env:   []string{"CADENCE_FEATURES_LEGACY_ROUTES_SUNSET"},
+		usage: "RFC 3339 time after which the unversioned routes are removed",
+		get:   func(c *Config) string { return c.Features.LegacyRoutesSunset.Format(time.RFC3339) },
+		set:   timeSetter(func(c *Config) *time.Time { return &c.Features.LegacyRoutesSunset }),
+	},
+	{
+		key:   "features.docs",
+		env:   []string{"CADENCE_FEATURES_DOCS"},
+		usage: "serve the OpenAPI document and Swagger UI",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Features.Docs) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Features.Docs }),
+	},
+	{
+		key:   "features.metrics",
+		env:   []string{"CADENCE_FEATURES_METRICS"},
+		usage: "serve Prometheus metrics at /metrics",
+		get:   func(c *Config) string { return strconv.FormatBool(c.Features.Metrics) },
+		set:   boolSetter(func(c *Config) *bool { return &c.Features.Metrics }),
+	},
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, raw string) error {
+	return func(c *Config, raw string) error {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 10s, got %q", raw)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// listSetter splits a comma separated value, dropping empty entries so that "" clears the list.
+func listSetter(field func(c *Config) *[]string) func(c *Config, raw string) error {
+	return func(c *Config, raw string) error {
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		*field(c) = values
+		return nil
+	}
+}
+
+func boolSetter(field func(c *Config) *bool) func(c *Config, raw string) error {
+	return func(c *Config, raw string) error {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", raw)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func timeSetter(field func(c *Config) *time.Time) func(c *Config, raw string) error {
+	return func(c *Config, raw string) error {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return fmt.Errorf("expected an RFC 3339 time, got %q", raw)
+		}
+		*field(c) = t
+		return nil
+	}
+}
+
+// Load builds the configuration from args (without the program name) and the environment. lookupEnv is normally
+// os.LookupEnv. A .env file in the working directory is read when present, but never overrides real environment
+// variables.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("cadence-api", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", "", "path to a YAML or TOML configuration file")
+	envFile := fs.String("env-file", ".env", "path to a dotenv file, ignored when missing")
+	for _, s := range settings {
+		fs.String(s.flagName(), s.get(&cfg), s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("invalid flags: %w", err)
+	}
+
+	env, err := withDotEnv(*envFile, lookupEnv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, err
+		}
+		if err = apply(&cfg, values, "file "+*configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	fromEnv := map[string]string{}
+	for _, s := range settings {
+		for _, name := range s.env {
+			if v, ok := env(name); ok {
+				fromEnv[s.key] = v
+				break
+			}
+		}
+	}
+	if err = apply(&cfg, fromEnv, "environment"); err != nil {
+		return Config{}, err
+	}
+
+	fromFlags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flagName() == f.Name {
+				fromFlags[s.key] = f.Value.String()
+			}
+		}
+	})
+	if err = apply(&cfg, fromFlags, "flags"); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Usage describes every setting with its file key, environment variable and flag.
+func Usage() string {
+	var b strings.Builder
+	b.WriteString("  --config        path to a YAML or TOML configuration file\n")
+	b.WriteString("  --env-file      path to a dotenv file, ignored when missing (default .env)\n")
+	for _, s := range settings {
+		fmt.Fprintf(&b, "  --%s (%s, file key %s)\n        %s\n", s.flagName(), s.env[0], s.key, s.usage)
+	}
+	return b.String()
+}
+
+func withDotEnv(path string, lookupEnv func(string) (string, bool)) (func(string) (string, bool), error) {
+	dotEnv, err := godotenv.Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lookupEnv, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %w", path, err)
+	}
+	return func(key string) (string, bool) {
+		if v, ok := lookupEnv(key); ok {
+			return v, true
+		}
+		v, ok := dotEnv[key]
+		return v, ok
+	}, nil
+}
+
+func apply(cfg *Config, values map[string]string, source string) error {
+	var problems []string
+	for _, s := range settings {
+		raw, ok := values[s.key]
+		if !ok {
+			continue
+		}
+		delete(values, s.key)
+		if err := s.set(cfg, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", s.key, err.Error()))
+		}
+	}
+	for key := range values {
+		problems = append(problems, fmt.Sprintf("%s: unknown setting", key))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &ValidationError{Source: source, Problems: problems}
+	}
+	return nil
+}
+
+// readFile parses a YAML (.yaml, .yml) or TOML (.toml) file into dotted keys such as "mongo.uri".
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	tree := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	return values, nil
+}
+
+// flatten turns nested tables into dotted keys. A table under a known setting, such as ratelimit.routes, is that
+// setting's value and is read like its environment variable: "<key> = <value>" entries separated by semicolons.
+func flatten(prefix string, tree map[string]interface{}, out map[string]string) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if !isSetting(key) {
+				flatten(key, value, out)
+				continue
+			}
+			entries := make([]string, 0, len(value))
+			for entryKey, entryValue := range value {
+				entries = append(entries, entryKey+" = "+fileValue(entryValue))
+			}
+			sort.Strings(entries)
+			out[key] = strings.Join(entries, "; ")
+		default:
+			out[key] = fileValue(value)
+		}
+	}
+}
+
+// fileValue formats a value read from a config file like the environment would spell it. Lists are comma separated.
+func fileValue(v interface{}) string {
+	switch value := v.(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fileValue(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func isSetting(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError lists every problem found in one configuration source.
+type ValidationError struct {
+	Source   string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration from %s:\n  %s", e.Source, strings.Join(e.Problems, "\n  "))
+}
+
+// Validate checks that the configuration is usable.
+func (c Config) Validate() error {
+	var problems []string
+	switch c.Storage {
+	case "mongo", "memory":
+	case "postgres":
+		if c.Postgres.DSN == "" {
+			problems = append(problems, "postgres.dsn: required when storage is postgres")
+		}
+	case "sqlite":
+		if c.SQLite.Path == "" {
+			problems = append(problems, "sqlite.path: required when storage is sqlite")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("storage: expected mongo, postgres, sqlite or memory, got %q", c.Storage))
+	}
+	if c.Postgres.ConnectTimeout <= 0 {
+		problems = append(problems, "postgres.connect_timeout: must be positive")
+	}
+	if _, _, err := net.SplitHostPort(c.HTTP.Addr); err != nil {
+		problems = append(problems, fmt.Sprintf("http.addr: expected host:port, got %q", c.HTTP.Addr))
+	}
+	if c.HTTP.DrainDelay < 0 {
+		problems = append(problems, "http.drain_delay: must not be negative")
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		problems = append(problems, "http.shutdown_timeout: must be positive")
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("http.trusted_proxies: %q is not an address or CIDR", proxy))
+			}
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		problems = append(problems, "tls.cert_file, tls.key_file: both must be set to enable TLS")
+	}
+	if c.TLS.ClientCAFile != "" && c.TLS.CertFile == "" {
+		problems = append(problems, "tls.client_ca_file: requires tls.cert_file and tls.key_file")
+	}
+	if c.TLS.ReloadInterval <= 0 {
+		problems = append(problems, "tls.reload_interval: must be positive")
+	}
+	if c.TLS.MinVersion != "1.2" && c.TLS.MinVersion != "1.3" {
+		problems = append(problems, fmt.Sprintf("tls.min_version: expected 1.2 or 1.3, got %q", c.TLS.MinVersion))
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" && c.CORS.AllowCredentials {
+			problems = append(problems, "cors.allowed_origins: * cannot be combined with cors.allow_credentials")
+		} else if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			problems = append(problems, fmt.Sprintf("cors.allowed_origins: %q must start with http:// or https://", origin))
+		}
+	}
+	if c.Security.HSTSMaxAge < 0 {
+		problems = append(problems, "security.hsts_max_age: must not be negative")
+	}
+	switch c.Security.FrameOptions {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		problems = append(problems, fmt.Sprintf("security.frame_options: expected DENY, SAMEORIGIN or empty, got %q", c.Security.FrameOptions))
+	}
+	if !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://") {
+		problems = append(problems, "mongo.uri: expected a mongodb:// or mongodb+srv:// connection string")
+	}
+	if c.Mongo.Database == "" || strings.ContainsAny(c.Mongo.Database, `/\. "$`) {
+		problems = append(problems, fmt.Sprintf("mongo.database: %q is not a valid database name", c.Mongo.Database))
+	}
+	if c.Mongo.ConnectTimeout <= 0 {
+		problems = append(problems, "mongo.connect_timeout: must be positive")
+	}
+	if c.Health.CheckTimeout <= 0 {
+		problems = append(problems, "health.check_timeout: must be positive")
+	}
+	if c.Trash.Retention < 0 {
+		problems = append(problems, "trash.retention: must not be negative")
+	}
+	if c.Trash.PurgeInterval <= 0 {
+		problems = append(problems, "trash.purge_interval: must be positive")
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("log.level: expected debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		problems = append(problems, fmt.Sprintf("log.format: expected json or text, got %q", c.Log.Format))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if _, _, err := net.SplitHostPort(c.Tracing.OTLPEndpoint); err != nil {
+			problems = append(problems, fmt.Sprintf("tracing.otlp_endpoint: expected host:port, got %q", c.Tracing.OTLPEndpoint))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter: expected none, stdout or otlp, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing.sample_ratio: must be between 0 and 1")
+	}
+	if c.RateLimit.Backend != "memory" && c.RateLimit.Backend != "mongo" {
+		problems = append(problems, fmt.Sprintf("ratelimit.backend: expected memory or mongo, got %q", c.RateLimit.Backend))
+	} else if c.RateLimit.Backend == "mongo" && c.Storage != "mongo" {
+		problems = append(problems, "ratelimit.backend: mongo requires mongo storage")
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Source: "merged settings", Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package configs_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/configs"
+	"github.com/alexander-littleton/cadence-api/pkg/common/ratelimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var (
+		args []string
+		env  map[string]string
+		dir  string
+		cfg  configs.Config
+		err  error
+	)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(contents), 0o600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		// point at a missing dotenv file so the repo's .env never leaks into the tests
+		args = []string{"--env-file", filepath.Join(dir, "missing.env")}
+		env = map[string]string{}
+	})
+
+	JustBeforeEach(func() {
+		cfg, err = configs.Load(args, func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		})
+	})
+
+	Context("nothing is configured", func() {
+		It("returns the defaults", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg).To(Equal(configs.Default()))
+		})
+	})
+
+	Context("every source sets the database", func() {
+		BeforeEach(func() {
+			path := writeFile("config.yaml", "mongo:\n  database: from_file\n  uri: mongodb://file:27017\nhttp:\n  addr: file:1\n")
+			args = append(args, "--config", path, "--mongo-database", "from_flag")
+			env["CADENCE_MONGO_DATABASE"] = "from_env"
+			env["CADENCE_MONGO_URI"] = "mongodb://env:27017"
+		})
+		It("prefers flags, then the environment, then the file", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.Mongo.Database).To(Equal("from_flag"))
+			Expect(cfg.Mongo.URI).To(Equal("mongodb://env:27017"))
+			Expect(cfg.HTTP.Addr).To(Equal("file:1"))
+		})
+	})
+
+	Context("a TOML file is used", func() {
+		BeforeEach(func() {
+			path := writeFile("config.toml", `
+[mongo]
+connect_timeout = "3s"
+
+[features]
+legacy_routes = false
+legacy_routes_sunset = 2030-01-02T03:04:05Z
+`)
+			args = append(args, "--config", path)
+		})
+		It("reads typed values", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.Mongo.ConnectTimeout).To(Equal(3 * time.Second))
+			Expect(cfg.Features.LegacyRoutes).To(BeFalse())
+			Expect(cfg.Features.LegacyRoutesSunset).To(BeTemporally("==", time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+		})
+	})
+
+	Context("a file lists values", func() {
+		BeforeEach(func() {
+			path := writeFile("config.yaml", `
+http:
+  trusted_proxies: [10.0.0.0/8, 192.168.0.0/16]
+cors:
+  allowed_origins:
+    - https://app.example.com
+    - https://admin.example.com
+`)
+			args = append(args, "--config", path)
+		})
+		It("reads every element", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.HTTP.TrustedProxies).To(Equal([]string{"10.0.0.0/8", "192.168.0.0/16"}))
+			Expect(cfg.CORS.AllowedOrigins).To(Equal([]string{"https://app.example.com", "https://admin.example.com"}))
+		})
+	})
+
+	Context("the legacy MONGOURI variable is set", func() {
+		BeforeEach(func() {
+			env["MONGOURI"] = "mongodb://legacy:27017"
+		})
+		It("is used for the mongo uri", func() {
+			Expect(cfg.Mongo.URI).To(Equal("mongodb://legacy:27017"))
+		})
+	})
+
+	Context("a dotenv file is present", func() {
+		BeforeEach(func() {
+			args = []string{"--env-file", writeFile(".env", "CADENCE_HTTP_ADDR=dotenv:1\nCADENCE_MONGO_DATABASE=dotenv\n")}
+			env["CADENCE_MONGO_DATABASE"] = "real_env"
+		})
+		It("fills in variables that are not already set", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.HTTP.Addr).To(Equal("dotenv:1"))
+			Expect(cfg.Mongo.Database).To(Equal("real_env"))
+		})
+	})
+
+	Context("the file contains an unknown key", func() {
+		BeforeEach(func() {
+			args = append(args, "--config", writeFile("config.yaml", "mongo:\n  url: nope\n"))
+		})
+		It("names the key", func() {
+			Expect(err).To(MatchError(ContainSubstring("mongo.url: unknown setting")))
+		})
+	})
+
+	Context("values are malformed", func() {
+		BeforeEach(func() {
+			env["CADENCE_MONGO_CONNECT_TIMEOUT"] = "soon"
+			env["CADENCE_FEATURES_DOCS"] = "maybe"
+		})
+		It("reports every problem", func() {
+			var validationErr *configs.ValidationError
+			Expect(err).To(BeAssignableToTypeOf(validationErr))
+			Expect(err.Error()).To(ContainSubstring("mongo.connect_timeout"))
+			Expect(err.Error()).To(ContainSubstring("features.docs"))
+		})
+	})
+
+	Context("rate limit policies are configured", func() {
+		BeforeEach(func() {
+			env["CADENCE_RATELIMIT_DEFAULT"] = "none"
+			env["CADENCE_RATELIMIT_ROUTES"] = "post /user = 5/1s per api_key,ip; GET /habits = 20/1m"
+		})
+		It("parses each route's policy", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.RateLimit.Default.Limit).To(BeZero())
+			Expect(cfg.RateLimit.Routes).To(Equal(map[string]ratelimit.Policy{
+				"POST /user":  {Limit: 5, Period: time.Second, Keys: []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP}},
+				"GET /habits": {Limit: 20, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByIP}},
+			}))
+		})
+	})
+
+	Context("rate limit policies are configured in a file", func() {
+		BeforeEach(func() {
+			path := writeFile("config.yaml", `
+ratelimit:
+  routes:
+    POST /user: 5/1s per api_key,ip
+    GET /habits: 20/1m
+`)
+			args = append(args, "--config", path)
+		})
+		It("parses each route's policy", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.RateLimit.Routes).To(Equal(map[string]ratelimit.Policy{
+				"POST /user":  {Limit: 5, Period: time.Second, Keys: []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP}},
+				"GET /habits": {Limit: 20, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByIP}},
+			}))
+		})
+	})
+
+	Context("api keys are issued to users in a file", func() {
+		BeforeEach(func() {
+			path := writeFile("config.yaml", `
+ratelimit:
+  user_api_keys:
+    64b7f3c2e4b0a1d2c3e4f5a6: [key-a, key-b]
+    64b7f3c2e4b0a1d2c3e4f5a7: key-c
+`)
+			args = append(args, "--config", path)
+		})
+		It("reads each user's keys", func() {
+			Expect(err).To(BeNil())
+			Expect(cfg.RateLimit.UserAPIKeys).To(Equal(map[string][]string{
+				"64b7f3c2e4b0a1d2c3e4f5a6": {"key-a", "key-b"},
+				"64b7f3c2e4b0a1d2c3e4f5a7": {"key-c"},
+			}))
+		})
+	})
+
+	Context("a rate limit policy is malformed", func() {
+		BeforeEach(func() {
+			env["CADENCE_RATELIMIT_ROUTES"] = "POST /user = 5 per ip"
+		})
+		It("names the setting", func() {
+			Expect(err).To(MatchError(ContainSubstring("ratelimit.routes")))
+		})
+	})
+
+	Context("postgres storage is selected without a dsn", func() {
+		BeforeEach(func() {
+			args = append(args, "--storage=postgres")
+		})
+		It("asks for the dsn", func() {
+			Expect(err).To(MatchError(ContainSubstring("postgres.dsn: required")))
+		})
+	})
+
+	Context("sqlite storage is selected without a path", func() {
+		BeforeEach(func() {
+			args = append(args, "--storage=sqlite", "--sqlite-path=")
+		})
+		It("asks for the path", func() {
+			Expect(err).To(MatchError(ContainSubstring("sqlite.path: required")))
+		})
+	})
+
+	Context("the trash is misconfigured", func() {
+		BeforeEach(func() {
+			args = append(args, "--trash-retention", "-1h", "--trash-purge-interval", "0s")
+		})
+		It("reports every problem", func() {
+			Expect(err).To(MatchError(ContainSubstring("trash.retention")))
+			Expect(err).To(MatchError(ContainSubstring("trash.purge_interval")))
+		})
+	})
+
+	Context("tracing is misconfigured", func() {
+		BeforeEach(func() {
+			args = append(args, "--tracing-exporter", "jaeger", "--tracing-sample-ratio", "2")
+		})
+		It("reports every problem", func() {
+			Expect(err).To(MatchError(ContainSubstring("tracing.exporter")))
+			Expect(err).To(MatchError(ContainSubstring("tracing.sample_ratio")))
+		})
+	})
+
+	Context("the merged configuration is invalid", func() {
+		BeforeEach(func() {
+			args = append(args, "--http-addr", "8080", "--http-drain-delay", "-1s", "--mongo-uri", "postgres://nope")
+		})
+		It("reports every problem", func() {
+			Expect(err).To(MatchError(ContainSubstring("http.addr")))
+			Expect(err).To(MatchError(ContainSubstring("http.drain_delay")))
+			Expect(err).To(MatchError(ContainSubstring("mongo.uri")))
+		})
+	})
+})
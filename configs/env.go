@@ -1,27 +0,0 @@
-package configs
-
-import (
-	"github.com/joho/godotenv"
-	"log"
-	"os"
-	"regexp"
-)
-
-const projectDirName = "cadence-api" // change to relevant project name
-
-func loadEnv() {
-	projectName := regexp.MustCompile(`^(.*` + projectDirName + `)`)
-	currentWorkDirectory, _ := os.Getwd()
-	rootPath := projectName.Find([]byte(currentWorkDirectory))
-
-	err := godotenv.Load(string(rootPath) + `/.env`)
-
-	if err != nil {
-		log.Fatalf("Error loading .env file")
-	}
-}
-
-func EnvMongoURI() string {
-	loadEnv()
-	return os.Getenv("MONGOURI")
-}
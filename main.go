@@ -1,27 +1,71 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
 	"github.com/alexander-littleton/cadence-api/configs"
-	userService "github.com/alexander-littleton/cadence-api/pkg/user"
-	userApi "github.com/alexander-littleton/cadence-api/pkg/user/api"
-	userRepo "github.com/alexander-littleton/cadence-api/pkg/user/repositories/mongo"
-	"github.com/gin-gonic/gin"
+	"github.com/alexander-littleton/cadence-api/pkg/app"
 )
 
 func main() {
-	//TODO: setup trusted proxies
-	router := gin.Default()
-	userController := userApi.New(
-		userService.New(
-			userRepo.NewUserRepository(
-				configs.GetCollection(configs.DB, "users"),
-			),
-		),
-	)
-	userController.RegisterRoutes(router)
-	err := router.Run("localhost:8080")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
+	cfg, err := configs.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print("Usage of cadence-api:\n" + configs.Usage())
+		return
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	application, err := app.New(cfg)
 	if err != nil {
-		fmt.Println(err.Error())
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err = application.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// migrate handles `cadence-api migrate up|down [steps]|status [flags]`, which takes the same configuration as the
+// server. down rolls back one migration unless a number of steps is given.
+func migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: cadence-api migrate up|down [steps]|status [flags]")
+	}
+	command, args := args[0], args[1:]
+	steps := 1
+	if command == "down" && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			steps, args = n, args[1:]
+		}
+	}
+
+	cfg, err := configs.Load(args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print("Usage of cadence-api migrate up|down [steps]|status:\n" + configs.Usage())
+		return
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err = app.Migrate(ctx, cfg, command, steps, os.Stdout); err != nil {
+		log.Fatal(err)
 	}
 }
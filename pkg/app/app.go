@@ -0,0 +1,437 @@
+// Package app wires the application together from a configs.Config. Nothing in the tree opens connections or reads
+// configuration at import time; everything is built here and handed to its dependents explicitly.
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/configs"
+	"github.com/alexander-littleton/cadence-api/pkg/common/health"
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/metrics"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/common/ratelimit"
+	"github.com/alexander-littleton/cadence-api/pkg/common/server"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/common/tracing"
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"github.com/alexander-littleton/cadence-api/pkg/common/versioning"
+	habitService "github.com/alexander-littleton/cadence-api/pkg/habit"
+	habitApi "github.com/alexander-littleton/cadence-api/pkg/habit/api"
+	habitRepositories "github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	habitMemory "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/memory"
+	habitRepo "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/mongo"
+	habitPostgres "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/postgres"
+	habitSQLite "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/sqlite"
+	userService "github.com/alexander-littleton/cadence-api/pkg/user"
+	userApi "github.com/alexander-littleton/cadence-api/pkg/user/api"
+	userRepositories "github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	userMemory "github.com/alexander-littleton/cadence-api/pkg/user/repositories/memory"
+	userRepo "github.com/alexander-littleton/cadence-api/pkg/user/repositories/mongo"
+	userPostgres "github.com/alexander-littleton/cadence-api/pkg/user/repositories/postgres"
+	userSQLite "github.com/alexander-littleton/cadence-api/pkg/user/repositories/sqlite"
+	"github.com/gin-gonic/gin"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+var tlsVersions = map[string]uint16{"1.2": tls.VersionTLS12, "1.3": tls.VersionTLS13}
+
+var legacyRoutesDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Storage is the set of repositories the services are built on. Users, Habits and CheckIns are required.
+type Storage struct {
+	Users    userRepositories.UserRepository
+	Habits   habitRepositories.HabitRepository
+	CheckIns habitRepositories.CheckInRepository
+	// Transactions groups calls to the repositories above into units of work. Without it they run one by one.
+	Transactions transaction.Runner
+	// Hook connects and disconnects the underlying database, if any.
+	Hook lifecycle.Hook
+	// Checks report whether the underlying database is reachable.
+	Checks []health.Checker
+	// RateLimits shares rate limit buckets between instances. It is required when the mongo rate limit backend is
+	// configured; buckets are kept in memory otherwise.
+	RateLimits ratelimit.Backend
+}
+
+// App holds the wired components. Nothing is connected or listening until Run or Lifecycle.Start is called.
+type App struct {
+	Config    configs.Config
+	Router    *gin.Engine
+	Users     userService.Service
+	Habits    habitService.Service
+	Lifecycle *lifecycle.Lifecycle
+	Health    *health.Registry
+	Metrics   *metrics.Metrics
+}
+
+type options struct {
+	storage *Storage
+	logger  *slog.Logger
+}
+
+type Option func(*options)
+
+// WithStorage replaces the Mongo backed repositories, e.g. with mocks or in-memory implementations in tests. No
+// database connection is made when it is used.
+func WithStorage(storage Storage) Option {
+	return func(o *options) {
+		o.storage = &storage
+	}
+}
+
+// WithLogger replaces the logger built from the configuration.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// New builds the application. It returns an error instead of exiting so callers decide how to report failures; the
+// tracer and storage it set up by then are shut down again.
+func New(cfg configs.Config, opts ...Option) (_ *App, err error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	logger := o.logger
+	if logger == nil {
+		if logger, err = logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format); err != nil {
+			return nil, err
+		}
+	}
+
+	m := metrics.New()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  "cadence-api",
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// stopped in reverse order if a later step fails, like the lifecycle hooks they become
+	var undo []func(ctx context.Context) error
+	undo = append(undo, shutdownTracing)
+	defer func() {
+		if err == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		for i := len(undo) - 1; i >= 0; i-- {
+			_ = undo[i](ctx)
+		}
+	}()
+
+	var storage Storage
+	switch {
+	case o.storage != nil:
+		storage = *o.storage
+	case cfg.Storage == "memory":
+		storage = newMemoryStorage()
+	case cfg.Storage == "postgres":
+		if storage, err = newPostgresStorage(cfg.Postgres, logger); err != nil {
+			return nil, err
+		}
+	case cfg.Storage == "sqlite":
+		if storage, err = newSQLiteStorage(cfg.SQLite, logger); err != nil {
+			return nil, err
+		}
+	default:
+		if storage, err = newMongoStorage(cfg.Mongo, logger, m); err != nil {
+			return nil, err
+		}
+	}
+	if storage.Hook.Stop != nil {
+		undo = append(undo, storage.Hook.Stop)
+	}
+	if storage.Users == nil || storage.Habits == nil || storage.CheckIns == nil {
+		return nil, errors.New("storage must provide the user, habit and check-in repositories")
+	}
+	if storage.Hook.Name == "" {
+		storage.Hook.Name = "storage"
+	}
+	if storage.Transactions == nil {
+		storage.Transactions = transaction.NoOp()
+	}
+
+	users := userService.New(storage.Users, storage.Transactions, logger, m,
+		storage.CheckIns.DeleteCheckInsByUserId,
+		storage.Habits.DeleteHabitsByUserId,
+	)
+	habits := habitService.New(storage.Habits, storage.CheckIns, users, storage.Transactions, logger, m)
+
+	healthRegistry := health.New(cfg.Health.CheckTimeout, cfg.Admin.Token)
+	for _, check := range storage.Checks {
+		healthRegistry.Register(check)
+	}
+
+	var limits ratelimit.Backend = ratelimit.NewMemoryBackend()
+	if cfg.RateLimit.Backend == "mongo" {
+		// falling back to memory would quietly give every instance its own limits
+		if storage.RateLimits == nil {
+			return nil, errors.New("ratelimit.backend: mongo requires storage that shares rate limits")
+		}
+		limits = storage.RateLimits
+	}
+	limiter := ratelimit.New(limits, rateLimitRules(cfg.RateLimit), logger)
+
+	router, err := newRouter(cfg, logger, m, limiter, users, habits, healthRegistry)
+	if err != nil {
+		return nil, err
+	}
+
+	// hooks stop in reverse order: readiness fails first, then the server drains, then storage is disconnected and
+	// finally the remaining spans are flushed
+	l := lifecycle.New()
+	l.Append(lifecycle.Hook{Name: "tracing", Stop: shutdownTracing})
+	l.Append(storage.Hook)
+	if cfg.Trash.Retention > 0 {
+		l.AppendWorker("trash purge", func(ctx context.Context) error {
+			return habitService.PurgeTrash(ctx, habits, cfg.Trash.Retention, cfg.Trash.PurgeInterval, logger)
+		})
+	}
+	srv := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	if cfg.HTTP.H2C {
+		srv.Handler = server.H2C(router)
+	}
+	if cfg.TLS.CertFile != "" {
+		reloader, err := server.NewCertReloader(server.TLSFiles{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			MinVersion:   tlsVersions[cfg.TLS.MinVersion],
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = reloader.Config()
+		l.AppendWorker("tls reload", func(ctx context.Context) error {
+			return reloader.Watch(ctx, cfg.TLS.ReloadInterval)
+		})
+	}
+	server.Register(l, srv)
+	l.Append(lifecycle.Hook{
+		Name: "health",
+		// stopped before the server, which keeps serving for the drain delay once readiness fails
+		Stop: func(ctx context.Context) error {
+			healthRegistry.Drain()
+			timer := time.NewTimer(cfg.HTTP.DrainDelay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+
+	return &App{
+		Config:    cfg,
+		Router:    router,
+		Users:     users,
+		Habits:    habits,
+		Lifecycle: l,
+		Health:    healthRegistry,
+		Metrics:   m,
+	}, nil
+}
+
+// Run starts every subsystem and blocks until ctx is cancelled or a subsystem fails, then stops everything within the
+// configured drain delay and shutdown timeout.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Lifecycle.Start(ctx); err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case runErr = <-a.Lifecycle.Failed():
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.Config.HTTP.DrainDelay+a.Config.HTTP.ShutdownTimeout)
+	defer cancel()
+	stopErr := a.Lifecycle.Stop(stopCtx)
+	if runErr != nil {
+		return runErr
+	}
+	return stopErr
+}
+
+func newMongoStorage(cfg configs.MongoConfig, logger *slog.Logger, m *metrics.Metrics) (Storage, error) {
+	client, err := mongodb.NewClient(cfg.URI, mongodb.CombineMonitors(m.CommandMonitor(), tracing.CommandMonitor()))
+	if err != nil {
+		return Storage{}, err
+	}
+	db := client.Database(cfg.Database)
+	hook := mongodb.Hook(client, cfg.ConnectTimeout)
+	if cfg.MigrateOnStart {
+		connect := hook.Start
+		hook.Start = func(ctx context.Context) error {
+			if err := connect(ctx); err != nil {
+				return err
+			}
+			if _, err := mongodb.NewMigrator(db, mongodb.Migrations).Up(ctx); err != nil {
+				_ = client.Disconnect(context.Background())
+				return err
+			}
+			return nil
+		}
+	}
+	return Storage{
+		Users:        userRepo.NewUserRepository(db.Collection("users"), logger),
+		Habits:       habitRepo.NewHabitRepository(db.Collection("habits"), logger),
+		CheckIns:     habitRepo.NewCheckInRepository(db.Collection("check_ins"), logger),
+		Transactions: mongodb.NewTransactionRunner(client, logger),
+		Hook:         hook,
+		Checks:       []health.Checker{mongodb.HealthChecker(client)},
+		RateLimits:   ratelimit.NewMongoBackend(db.Collection("rate_limits")),
+	}, nil
+}
+
+func newPostgresStorage(cfg configs.PostgresConfig, logger *slog.Logger) (Storage, error) {
+	pool, err := postgres.NewPool(cfg.DSN)
+	if err != nil {
+		return Storage{}, err
+	}
+	return Storage{
+		Users:        userPostgres.NewUserRepository(pool, logger),
+		Habits:       habitPostgres.NewHabitRepository(pool, logger),
+		CheckIns:     habitPostgres.NewCheckInRepository(pool, logger),
+		Transactions: postgres.NewTransactionRunner(pool),
+		Hook:         postgres.Hook(pool, cfg.ConnectTimeout),
+		Checks:       []health.Checker{postgres.HealthChecker(pool)},
+	}, nil
+}
+
+// newSQLiteStorage keeps everything in a single local file, so the API runs without any other process.
+func newSQLiteStorage(cfg configs.SQLiteConfig, logger *slog.Logger) (Storage, error) {
+	db, err := sqlite.Open(cfg.Path)
+	if err != nil {
+		return Storage{}, err
+	}
+	return Storage{
+		Users:        userSQLite.NewUserRepository(db, logger),
+		Habits:       habitSQLite.NewHabitRepository(db, logger),
+		CheckIns:     habitSQLite.NewCheckInRepository(db, logger),
+		Transactions: sqlite.NewTransactionRunner(db),
+		Hook:         sqlite.Hook(db),
+		Checks:       []health.Checker{sqlite.HealthChecker(db)},
+	}, nil
+}
+
+// newMemoryStorage keeps everything in process, for demos and local runs without a database. Nothing survives a
+// restart, so units of work are not made atomic either.
+func newMemoryStorage() Storage {
+	return Storage{
+		Users:        userMemory.NewUserRepository(),
+		Habits:       habitMemory.NewHabitRepository(),
+		CheckIns:     habitMemory.NewCheckInRepository(),
+		Transactions: transaction.NoOp(),
+	}
+}
+
+func rateLimitRules(cfg configs.RateLimitConfig) ratelimit.Rules {
+	rules := ratelimit.Rules{Routes: cfg.Routes, APIKeys: cfg.APIKeys, UserAPIKeys: cfg.UserAPIKeys}
+	if cfg.Default.Limit > 0 {
+		rules.Default = &cfg.Default
+	}
+	return rules
+}
+
+func newRouter(
+	cfg configs.Config,
+	logger *slog.Logger,
+	m *metrics.Metrics,
+	limiter *ratelimit.Limiter,
+	users userService.Service,
+	habits habitService.Service,
+	healthRegistry *health.Registry,
+) (*gin.Engine, error) {
+	router := gin.New()
+	// lets services read request scoped values set on the request context through *gin.Context
+	router.ContextWithFallback = true
+	// ClientIP, used for logs and rate limits, only believes X-Forwarded-For when it was set by one of these
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(
+		middleware.RequestId(),
+		middleware.UserId(),
+		tracing.Middleware(),
+		middleware.RequestLogger(logger),
+		m.Middleware(),
+		middleware.ErrorHandler(),
+		// inside the middlewares above so that they see a panic as a 500
+		middleware.Recovery(logger),
+		middleware.SecurityHeaders(middleware.SecurityPolicy{
+			HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+			HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+			ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy,
+			FrameOptions:          cfg.Security.FrameOptions,
+			TrustedProxies:        cfg.HTTP.TrustedProxies,
+		}),
+	)
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		router.Use(middleware.CORS(middleware.CORSPolicy{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		}))
+	}
+
+	spec := openapi.NewGenerator(openapi.Info{Title: "cadence-api", Version: "1.0.0"})
+	healthRegistry.RegisterRoutes(router)
+	spec.Add(healthRegistry)
+	if cfg.Features.Metrics {
+		m.RegisterRoutes(router)
+		spec.Add(m)
+	}
+	// gin binds middleware to routes as they are registered, so probes and scrapes above are never limited
+	if cfg.RateLimit.Enabled {
+		router.Use(limiter.Middleware())
+	}
+	controllers := []versioning.Controller{userApi.New(users), habitApi.New(habits)}
+	versioning.Mount(router, spec, versioning.Version{Prefix: "/v1"}, controllers...)
+	if cfg.Features.LegacyRoutes {
+		// unversioned routes are kept for existing clients until the sunset date
+		versioning.Mount(router, spec, versioning.Version{
+			DeprecatedAt: legacyRoutesDeprecatedAt,
+			Sunset:       cfg.Features.LegacyRoutesSunset,
+			Successor:    "/v1",
+			Legacy:       true,
+		}, controllers...)
+	}
+	if cfg.Features.Docs {
+		spec.RegisterRoutes(router)
+	}
+	if _, err := spec.Generate(router.Routes()); err != nil {
+		return nil, fmt.Errorf("failed to generate openapi spec: %w", err)
+	}
+	return router, nil
+}
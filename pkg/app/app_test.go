@@ -0,0 +1,407 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/configs"
+	"github.com/alexander-littleton/cadence-api/pkg/app"
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/ratelimit"
+	habitMocks "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/mocks"
+	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	userMocks "github.com/alexander-littleton/cadence-api/pkg/user/repositories/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var (
+		ctrl        *gomock.Controller
+		userRepo    *userMocks.MockUserRepository
+		habitRepo   *habitMocks.MockHabitRepository
+		checkInRepo *habitMocks.MockCheckInRepository
+		cfg         configs.Config
+		closed      bool
+		target      *app.App
+		err         error
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		userRepo = userMocks.NewMockUserRepository(ctrl)
+		habitRepo = habitMocks.NewMockHabitRepository(ctrl)
+		checkInRepo = habitMocks.NewMockCheckInRepository(ctrl)
+		cfg = configs.Default()
+		cfg.HTTP.Addr = "127.0.0.1:0"
+		closed = false
+	})
+
+	JustBeforeEach(func() {
+		target, err = app.New(cfg, app.WithStorage(app.Storage{
+			Users:    userRepo,
+			Habits:   habitRepo,
+			CheckIns: checkInRepo,
+			Hook: lifecycle.Hook{
+				Stop: func(context.Context) error {
+					closed = true
+					return nil
+				},
+			},
+		}), app.WithLogger(logging.Discard()))
+	})
+
+	It("serves requests from the supplied storage without connecting to mongo", func() {
+		Expect(err).To(BeNil())
+		userRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@test.com").Return(domain.User{}, cadence_errors.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v1/user/test@test.com", nil)
+		target.Router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(404))
+	})
+
+	It("keeps the legacy error shape on the unversioned routes", func() {
+		Expect(err).To(BeNil())
+		userRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@test.com").Return(domain.User{}, cadence_errors.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/user/test@test.com", nil)
+		target.Router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(404))
+		var body struct {
+			Message string `json:"message"`
+			Data    struct {
+				Data string `json:"data"`
+			} `json:"data"`
+		}
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Message).To(Equal("error"))
+		Expect(body.Data.Data).NotTo(BeEmpty())
+	})
+
+	It("keeps the legacy success shape on the unversioned routes and documents it", func() {
+		Expect(err).To(BeNil())
+		userRepo.EXPECT().GetUserByEmail(gomock.Any(), "test@test.com").Return(domain.User{Email: "test@test.com"}, nil)
+
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/user/test@test.com", nil)
+		target.Router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(200))
+		var body struct {
+			Message string `json:"message"`
+			Data    struct {
+				Data domain.User `json:"data"`
+			} `json:"data"`
+		}
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Message).To(Equal("success"))
+		Expect(body.Data.Data.Email).To(Equal("test@test.com"))
+
+		w = httptest.NewRecorder()
+		request, _ = http.NewRequest("GET", "/openapi.json", nil)
+		target.Router.ServeHTTP(w, request)
+		schemaOf := func(path string) string {
+			var doc struct {
+				Paths map[string]map[string]struct {
+					Responses map[string]struct {
+						Content map[string]struct {
+							Schema struct {
+								Ref string `json:"$ref"`
+							} `json:"schema"`
+						} `json:"content"`
+					} `json:"responses"`
+				} `json:"paths"`
+			}
+			Expect(json.Unmarshal(w.Body.Bytes(), &doc)).To(Succeed())
+			return doc.Paths[path]["get"].Responses["200"].Content["application/json"].Schema.Ref
+		}
+		Expect(schemaOf("/user/{email}")).To(HaveSuffix("/LegacyUser"))
+		Expect(schemaOf("/v1/user/{email}")).To(HaveSuffix("/EnvelopeUser"))
+	})
+
+	It("runs until the context is cancelled, then closes the storage", func() {
+		// the trash is purged in the background as soon as the application starts
+		checkInRepo.EXPECT().PurgeCheckIns(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+		habitRepo.EXPECT().PurgeHabits(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan error)
+		go func() {
+			done <- target.Run(ctx)
+		}()
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(closed).To(BeTrue())
+	})
+
+	Context("clients are rate limited by address", func() {
+		send := func(forwardedFor string) int {
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v1/user/test@test.com", nil)
+			request.RemoteAddr = "10.0.0.1:4321"
+			request.Header.Set("X-Forwarded-For", forwardedFor)
+			target.Router.ServeHTTP(w, request)
+			return w.Code
+		}
+
+		BeforeEach(func() {
+			cfg.RateLimit.Routes = map[string]ratelimit.Policy{
+				"GET /user/:email": {Limit: 1, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByIP}},
+			}
+			userRepo.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Return(domain.User{}, cadence_errors.ErrNotFound).AnyTimes()
+		})
+		It("ignores X-Forwarded-For from untrusted peers", func() {
+			Expect(send("198.51.100.1")).To(Equal(404))
+			Expect(send("198.51.100.2")).To(Equal(429))
+		})
+
+		Context("the load balancer is a trusted proxy", func() {
+			BeforeEach(func() {
+				cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8"}
+			})
+			It("limits each forwarded client separately", func() {
+				Expect(send("198.51.100.1")).To(Equal(404))
+				Expect(send("198.51.100.2")).To(Equal(404))
+				Expect(send("198.51.100.1")).To(Equal(429))
+			})
+		})
+	})
+
+	It("rejects storage without every repository", func() {
+		_, err := app.New(cfg, app.WithStorage(app.Storage{Users: userRepo, Habits: habitRepo}), app.WithLogger(logging.Discard()))
+		Expect(err).To(MatchError(ContainSubstring("check-in")))
+	})
+
+	Context("the mongo rate limit backend is configured", func() {
+		BeforeEach(func() {
+			cfg.RateLimit.Backend = "mongo"
+		})
+		It("refuses storage that cannot share rate limits and closes it", func() {
+			Expect(err).To(MatchError(ContainSubstring("ratelimit.backend")))
+			Expect(closed).To(BeTrue())
+		})
+	})
+
+	Context("a web client origin is allowed", func() {
+		BeforeEach(func() {
+			cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+		})
+		It("answers preflight requests with security headers", func() {
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("OPTIONS", "/v1/user", nil)
+			request.Header.Set("Origin", "https://app.example.com")
+			request.Header.Set("Access-Control-Request-Method", "POST")
+			target.Router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://app.example.com"))
+			Expect(w.Header().Get("X-Frame-Options")).To(Equal("DENY"))
+		})
+	})
+
+	Context("memory storage is configured", func() {
+		It("stores users without any database", func() {
+			memoryCfg := cfg
+			memoryCfg.Storage = "memory"
+			memoryApp, err := app.New(memoryCfg, app.WithLogger(logging.Discard()))
+			Expect(err).To(BeNil())
+
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/v1/user", strings.NewReader(`{"email":"memory@test.com"}`))
+			memoryApp.Router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+
+			w = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/v1/user/memory@test.com", nil)
+			memoryApp.Router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects updates based on a version that was replaced", func() {
+			memoryCfg := cfg
+			memoryCfg.Storage = "memory"
+			memoryApp, err := app.New(memoryCfg, app.WithLogger(logging.Discard()))
+			Expect(err).To(BeNil())
+
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/v1/user", strings.NewReader(`{"email":"first@test.com"}`))
+			memoryApp.Router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var created struct {
+				Data domain.User `json:"data"`
+			}
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			firstETag := w.Header().Get("ETag")
+
+			update := func(email string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				body := strings.NewReader(`{"email":"` + email + `"}`)
+				request, _ := http.NewRequest("PUT", "/v1/user/"+created.Data.Id.Hex(), body)
+				request.Header.Set("If-Match", firstETag)
+				memoryApp.Router.ServeHTTP(w, request)
+				return w
+			}
+			w = update("second@test.com")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("ETag")).NotTo(Equal(firstETag))
+
+			Expect(update("third@test.com").Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("deletes a user along with their habits and check-ins", func() {
+			memoryCfg := cfg
+			memoryCfg.Storage = "memory"
+			memoryApp, err := app.New(memoryCfg, app.WithLogger(logging.Discard()))
+			Expect(err).To(BeNil())
+			send := func(method, path, body string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				request, _ := http.NewRequest(method, path, strings.NewReader(body))
+				memoryApp.Router.ServeHTTP(w, request)
+				return w
+			}
+			var created struct {
+				Data struct {
+					Id string `json:"id"`
+				} `json:"data"`
+			}
+
+			w := send("POST", "/v1/user", `{"email":"leaving@test.com"}`)
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			userId := created.Data.Id
+			w = send("POST", "/v1/habit", `{"name":"read","user_id":"`+userId+`"}`)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			habitId := created.Data.Id
+			Expect(send("POST", "/v1/habit/"+habitId+"/check-ins", "").Code).To(Equal(http.StatusCreated))
+
+			Expect(send("DELETE", "/v1/user/"+userId, "").Code).To(Equal(http.StatusNoContent))
+			Expect(send("GET", "/v1/habit/"+habitId, "").Code).To(Equal(http.StatusNotFound))
+			Expect(send("GET", "/v1/habit/"+habitId+"/check-ins", "").Code).To(Equal(http.StatusNotFound))
+			Expect(send("DELETE", "/v1/user/"+userId, "").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("moves deleted habits to the trash and restores them with their check-ins", func() {
+			memoryCfg := cfg
+			memoryCfg.Storage = "memory"
+			memoryApp, err := app.New(memoryCfg, app.WithLogger(logging.Discard()))
+			Expect(err).To(BeNil())
+			send := func(method, path, body string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				request, _ := http.NewRequest(method, path, strings.NewReader(body))
+				memoryApp.Router.ServeHTTP(w, request)
+				return w
+			}
+			var created struct {
+				Data struct {
+					Id string `json:"id"`
+				} `json:"data"`
+			}
+			var listed struct {
+				Data []struct {
+					Id string `json:"id"`
+				} `json:"data"`
+			}
+
+			w := send("POST", "/v1/user", `{"email":"undo@test.com"}`)
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			userId := created.Data.Id
+			w = send("POST", "/v1/habit", `{"name":"read","user_id":"`+userId+`"}`)
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			habitId := created.Data.Id
+			Expect(send("POST", "/v1/habit/"+habitId+"/check-ins", "").Code).To(Equal(http.StatusCreated))
+
+			Expect(send("DELETE", "/v1/habit/"+habitId, "").Code).To(Equal(http.StatusNoContent))
+			Expect(send("GET", "/v1/habit/"+habitId, "").Code).To(Equal(http.StatusNotFound))
+			w = send("GET", "/v1/habits/trash?user_id="+userId, "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(json.Unmarshal(w.Body.Bytes(), &listed)).To(Succeed())
+			Expect(listed.Data).To(HaveLen(1))
+			Expect(listed.Data[0].Id).To(Equal(habitId))
+
+			Expect(send("POST", "/v1/habit/"+habitId+"/restore", "").Code).To(Equal(http.StatusOK))
+			w = send("GET", "/v1/habit/"+habitId+"/check-ins", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(json.Unmarshal(w.Body.Bytes(), &listed)).To(Succeed())
+			Expect(listed.Data).To(HaveLen(1))
+			Expect(send("POST", "/v1/habit/"+habitId+"/restore", "").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("hides archived habits from listings and stats unless asked for", func() {
+			memoryCfg := cfg
+			memoryCfg.Storage = "memory"
+			memoryApp, err := app.New(memoryCfg, app.WithLogger(logging.Discard()))
+			Expect(err).To(BeNil())
+			send := func(method, path, body string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				request, _ := http.NewRequest(method, path, strings.NewReader(body))
+				memoryApp.Router.ServeHTTP(w, request)
+				return w
+			}
+			var created struct {
+				Data struct {
+					Id string `json:"id"`
+				} `json:"data"`
+			}
+			var listed struct {
+				Data []struct {
+					Id string `json:"id"`
+				} `json:"data"`
+			}
+			var stats struct {
+				Data struct {
+					Habits   int64 `json:"habits"`
+					CheckIns int64 `json:"check_ins"`
+				} `json:"data"`
+			}
+
+			w := send("POST", "/v1/user", `{"email":"retired@test.com"}`)
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			userId := created.Data.Id
+			w = send("POST", "/v1/habit", `{"name":"read","user_id":"`+userId+`"}`)
+			Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+			habitId := created.Data.Id
+			Expect(send("POST", "/v1/habit/"+habitId+"/check-ins", "").Code).To(Equal(http.StatusCreated))
+
+			Expect(send("POST", "/v1/habit/"+habitId+"/archive", "").Code).To(Equal(http.StatusOK))
+			Expect(send("POST", "/v1/habit/"+habitId+"/check-ins", "").Code).To(Equal(http.StatusBadRequest))
+			w = send("GET", "/v1/habits?user_id="+userId, "")
+			Expect(json.Unmarshal(w.Body.Bytes(), &listed)).To(Succeed())
+			Expect(listed.Data).To(BeEmpty())
+			w = send("GET", "/v1/habits?archived=include&user_id="+userId, "")
+			Expect(json.Unmarshal(w.Body.Bytes(), &listed)).To(Succeed())
+			Expect(listed.Data).To(HaveLen(1))
+
+			w = send("GET", "/v1/habits/stats?user_id="+userId, "")
+			Expect(json.Unmarshal(w.Body.Bytes(), &stats)).To(Succeed())
+			Expect(stats.Data.Habits).To(BeZero())
+			w = send("GET", "/v1/habits/stats?archived=include&user_id="+userId, "")
+			Expect(json.Unmarshal(w.Body.Bytes(), &stats)).To(Succeed())
+			Expect(stats.Data.Habits).To(Equal(int64(1)))
+			Expect(stats.Data.CheckIns).To(Equal(int64(1)))
+
+			w = send("GET", "/v1/habit/"+habitId+"/check-ins", "")
+			Expect(json.Unmarshal(w.Body.Bytes(), &listed)).To(Succeed())
+			Expect(listed.Data).To(HaveLen(1))
+		})
+	})
+
+	Context("legacy routes are disabled", func() {
+		BeforeEach(func() {
+			cfg.Features.LegacyRoutes = false
+		})
+		It("only serves versioned routes", func() {
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/user/test@test.com", nil)
+			target.Router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+})
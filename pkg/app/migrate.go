@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/configs"
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+)
+
+// Migrate runs a migration command against the configured storage without starting the server, writing what it did
+// to out. command is up to apply pending migrations, down to roll back the latest steps migrations or status to list
+// them. Postgres and SQLite only support up; their migrations cannot be rolled back.
+func Migrate(ctx context.Context, cfg configs.Config, command string, steps int, out io.Writer) error {
+	switch command {
+	case "up", "status", "down":
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+
+	switch cfg.Storage {
+	case "mongo":
+		return migrateMongo(ctx, cfg.Mongo, command, steps, out)
+	case "postgres", "sqlite":
+		if command != "up" {
+			return fmt.Errorf("migrate %s is only supported for mongo storage", command)
+		}
+		var hook lifecycle.Hook
+		if cfg.Storage == "postgres" {
+			pool, err := postgres.NewPool(cfg.Postgres.DSN)
+			if err != nil {
+				return err
+			}
+			hook = postgres.Hook(pool, cfg.Postgres.ConnectTimeout)
+		} else {
+			db, err := sqlite.Open(cfg.SQLite.Path)
+			if err != nil {
+				return err
+			}
+			hook = sqlite.Hook(db)
+		}
+		// both hooks apply pending migrations when they start
+		if err := hook.Start(ctx); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "%s is up to date\n", cfg.Storage)
+		return hook.Stop(context.Background())
+	default:
+		return fmt.Errorf("%s storage has no migrations", cfg.Storage)
+	}
+}
+
+func migrateMongo(ctx context.Context, cfg configs.MongoConfig, command string, steps int, out io.Writer) error {
+	client, err := mongodb.NewClient(cfg.URI, nil)
+	if err != nil {
+		return err
+	}
+	hook := mongodb.Hook(client, cfg.ConnectTimeout)
+	if err = hook.Start(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = hook.Stop(context.Background()) }()
+
+	migrator := mongodb.NewMigrator(client.Database(cfg.Database), mongodb.Migrations)
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			_, _ = fmt.Fprintf(out, "applied %d %s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			_, _ = fmt.Fprintln(out, "mongo is up to date")
+		}
+		return err
+	case "down":
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, migration := range rolledBack {
+			_, _ = fmt.Fprintf(out, "rolled back %d %s\n", migration.Version, migration.Name)
+		}
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if !status.AppliedAt.IsZero() {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return w.Flush()
+	}
+}
@@ -0,0 +1,67 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+
+	"github.com/alexander-littleton/cadence-api/configs"
+	"github.com/alexander-littleton/cadence-api/pkg/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrate", func() {
+	var (
+		cfg     configs.Config
+		command string
+		out     bytes.Buffer
+		err     error
+	)
+
+	BeforeEach(func() {
+		cfg = configs.Default()
+		cfg.Storage = "sqlite"
+		cfg.SQLite.Path = filepath.Join(GinkgoT().TempDir(), "cadence.db")
+		command = "up"
+		out.Reset()
+	})
+
+	JustBeforeEach(func() {
+		err = app.Migrate(context.TODO(), cfg, command, 1, &out)
+	})
+
+	Context("sqlite storage is migrated up", func() {
+		It("creates the schema", func() {
+			Expect(err).To(BeNil())
+			Expect(out.String()).To(Equal("sqlite is up to date\n"))
+		})
+	})
+
+	Context("sqlite storage is rolled back", func() {
+		BeforeEach(func() {
+			command = "down"
+		})
+		It("is refused", func() {
+			Expect(err).To(MatchError("migrate down is only supported for mongo storage"))
+		})
+	})
+
+	Context("the storage keeps nothing", func() {
+		BeforeEach(func() {
+			cfg.Storage = "memory"
+		})
+		It("has nothing to migrate", func() {
+			Expect(err).To(MatchError("memory storage has no migrations"))
+		})
+	})
+
+	Context("the command is unknown", func() {
+		BeforeEach(func() {
+			command = "sideways"
+		})
+		It("names the valid commands", func() {
+			Expect(err).To(MatchError(ContainSubstring("expected up, down or status")))
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package cadence_errors
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding converts a request binding failure into a Validation error, listing each field that failed its
+// validate tag so clients can highlight them.
+func FromBinding(err error, message string) *Error {
+	e := Wrap(err, Validation, "request.invalid_body", message)
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return e
+	}
+	for _, fieldErr := range validationErrs {
+		e = e.WithField(strings.ToLower(fieldErr.Field()), "failed "+fieldErr.Tag()+" validation")
+	}
+	return e
+}
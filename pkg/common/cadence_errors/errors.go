@@ -2,8 +2,155 @@ package cadence_errors
 
 import (
 	"errors"
-	"go.mongodb.org/mongo-driver/mongo"
+	"fmt"
 )
 
-var ErrNotFound = mongo.ErrNoDocuments
-var ValidationErr = errors.New("validation failed")
+// Kind classifies an error independently of the layer or storage engine that produced it. Controllers and middleware
+// decide how to respond to a failure based on its Kind alone.
+type Kind uint8
+
+const (
+	Internal Kind = iota
+	NotFound
+	Validation
+	Conflict
+	Unauthorized
+	Forbidden
+	RateLimited
+	// PreconditionFailed reports that a condition the client attached to a request, such as If-Match, does not hold.
+	PreconditionFailed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case NotFound:
+		return "not_found"
+	case Validation:
+		return "validation"
+	case Conflict:
+		return "conflict"
+	case Unauthorized:
+		return "unauthorized"
+	case Forbidden:
+		return "forbidden"
+	case RateLimited:
+		return "rate_limited"
+	case PreconditionFailed:
+		return "precondition_failed"
+	default:
+		return "internal"
+	}
+}
+
+// FieldError describes why a single input field failed validation.
+type FieldError struct {
+	Field  string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Error is the typed error used throughout the application. Code is a stable, machine readable identifier (e.g.
+// "user.email_taken") and Details carries any structured context that is safe to return to a client. Message is the
+// client facing description; the wrapped Err is for logs only.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details map[string]interface{}
+	Fields  []FieldError
+	Err     error
+}
+
+// New creates an Error of the given kind.
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Wrap creates an Error of the given kind that wraps err.
+func Wrap(err error, kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// WithDetail returns a copy of the error with the key/value added to its details.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	details[key] = value
+
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// WithField returns a copy of the error with a field validation failure appended.
+func (e *Error) WithField(field, reason string) *Error {
+	clone := *e
+	clone.Fields = append(append([]FieldError(nil), e.Fields...), FieldError{Field: field, Reason: reason})
+	return &clone
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Kind.String()
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same kind. A target without a code matches any code, which lets
+// callers use the sentinel errors below with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind && (t.Code == "" || t.Code == e.Code)
+}
+
+// KindOf returns the Kind of the first *Error in err's chain, or Internal if there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+var (
+	ErrNotFound     = New(NotFound, "", "not found")
+	ValidationErr   = New(Validation, "", "validation failed")
+	ErrConflict     = New(Conflict, "", "conflict")
+	ErrUnauthorized = New(Unauthorized, "", "unauthorized")
+	ErrForbidden    = New(Forbidden, "", "forbidden")
+	ErrRateLimited  = New(RateLimited, "", "rate limited")
+	ErrInternal     = New(Internal, "", "internal error")
+
+	ErrPreconditionFailed = New(PreconditionFailed, "", "precondition failed")
+	// ErrStaleVersion is returned by repositories when an update was based on a version of a resource that has since
+	// been replaced.
+	ErrStaleVersion = New(Conflict, "version.stale", "resource was modified by another request")
+)
@@ -0,0 +1,56 @@
+// Package etag converts resource versions to and from the entity tags carried by the ETag and If-Match headers.
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Format renders version as a strong entity tag.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Set adds the ETag header for version to the response.
+func Set(ctx *gin.Context, version int64) {
+	ctx.Header("ETag", Format(version))
+}
+
+// IfMatch reads the request's If-Match header; ok is false when it is absent. The wildcard "*" yields version 0, which
+// services treat as any version. If-Match requires a strong comparison, so weak tags and tags this API never issued
+// fail with a PreconditionFailed error.
+func IfMatch(ctx *gin.Context) (version int64, ok bool, err error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	if header == "*" {
+		return 0, true, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, true, cadence_errors.New(cadence_errors.Validation, "etag.list_unsupported", "If-Match must contain a single entity tag").
+			WithField("If-Match", "must contain a single entity tag")
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err == nil && strings.HasPrefix(header, `"`) {
+		version, err = strconv.ParseInt(unquoted, 10, 64)
+	}
+	if err != nil || version < 1 {
+		return 0, true, cadence_errors.New(cadence_errors.PreconditionFailed, "etag.mismatch", "If-Match does not match the current version")
+	}
+	return version, true, nil
+}
+
+// Precondition converts ErrStaleVersion into a PreconditionFailed error, for updates whose expected version came from
+// If-Match rather than from a version read earlier in the same request.
+func Precondition(err error) error {
+	if errors.Is(err, cadence_errors.ErrStaleVersion) {
+		return cadence_errors.Wrap(err, cadence_errors.PreconditionFailed, "etag.mismatch", "If-Match does not match the current version")
+	}
+	return err
+}
@@ -0,0 +1,13 @@
+package etag_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEtag(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Etag Suite")
+}
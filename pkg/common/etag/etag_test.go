@@ -0,0 +1,72 @@
+package etag_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/etag"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IfMatch", func() {
+	ifMatch := func(header string) (int64, bool, error) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request, _ = http.NewRequest("PUT", "/", nil)
+		if header != "" {
+			ctx.Request.Header.Set("If-Match", header)
+		}
+		return etag.IfMatch(ctx)
+	}
+
+	It("reads the version from a tag made by Format", func() {
+		version, ok, err := ifMatch(etag.Format(7))
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeTrue())
+		Expect(version).To(Equal(int64(7)))
+	})
+
+	It("reports an absent header", func() {
+		_, ok, err := ifMatch("")
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reads the wildcard as any version", func() {
+		version, ok, err := ifMatch("*")
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeTrue())
+		Expect(version).To(BeZero())
+	})
+
+	DescribeTable("fails the precondition for tags that cannot match",
+		func(header string) {
+			_, _, err := ifMatch(header)
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.PreconditionFailed))
+		},
+		Entry("weak", `W/"7"`),
+		Entry("unquoted", "7"),
+		Entry("not a version", `"abc"`),
+		Entry("zero", `"0"`),
+	)
+
+	It("rejects a list of tags", func() {
+		_, _, err := ifMatch(`"1", "2"`)
+		Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Validation))
+	})
+})
+
+var _ = Describe("Precondition", func() {
+	It("turns a stale version into a failed precondition", func() {
+		err := etag.Precondition(fmt.Errorf("failed to update: %w", cadence_errors.ErrStaleVersion))
+		Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.PreconditionFailed))
+	})
+
+	It("leaves other conflicts alone", func() {
+		conflict := cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "taken")
+		Expect(etag.Precondition(conflict)).To(Equal(conflict))
+	})
+})
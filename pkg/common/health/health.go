@@ -0,0 +1,224 @@
+// Package health exposes liveness and readiness endpoints backed by pluggable dependency checks.
+package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker is implemented by every component the API depends on, such as the database client, a mailer or a job
+// queue. Check should return promptly once ctx is done.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// CheckerFunc adapts a function to a Checker.
+func CheckerFunc(name string, check func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+type registration struct {
+	checker Checker
+	timeout time.Duration
+}
+
+// Registry runs the registered checks concurrently, each bounded by its own timeout.
+type Registry struct {
+	mu             sync.RWMutex
+	checks         []registration
+	defaultTimeout time.Duration
+	adminToken     string
+	draining       int32
+}
+
+// New creates a registry. Checks registered without a timeout use defaultTimeout. The detailed report is only
+// served to requests bearing adminToken; it is disabled when adminToken is empty.
+func New(defaultTimeout time.Duration, adminToken string) *Registry {
+	return &Registry{
+		defaultTimeout: defaultTimeout,
+		adminToken:     adminToken,
+	}
+}
+
+func (r *Registry) Register(checker Checker) {
+	r.RegisterWithTimeout(checker, r.defaultTimeout)
+}
+
+func (r *Registry) RegisterWithTimeout(checker Checker, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, registration{checker: checker, timeout: timeout})
+}
+
+// Drain marks the API as not ready so that load balancers stop sending traffic while it shuts down.
+func (r *Registry) Drain() {
+	atomic.StoreInt32(&r.draining, 1)
+}
+
+// Run executes every check and reports the API as down if any of them fail.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]registration(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, reg := range checks {
+		wg.Add(1)
+		go func(i int, reg registration) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, reg)
+		}(i, reg)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if atomic.LoadInt32(&r.draining) == 1 {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, reg registration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, reg.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- reg.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		// the checker ignored its context; don't let it hold up the report
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Name: reg.checker.Name(), Status: StatusUp, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// RegisterRoutes mounts /healthz (liveness), /readyz (readiness) and /healthz/details (the full report, admin only).
+func (r *Registry) RegisterRoutes(router gin.IRouter) {
+	router.GET("/healthz", r.liveness)
+	router.GET("/readyz", r.readiness)
+	router.GET("/healthz/details", r.details)
+}
+
+func (r *Registry) liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Report{Status: StatusUp})
+}
+
+func (r *Registry) readiness(ctx *gin.Context) {
+	report := r.Run(ctx.Request.Context())
+	ctx.JSON(statusCode(report), Report{Status: report.Status})
+}
+
+func (r *Registry) details(ctx *gin.Context) {
+	if r.adminToken == "" {
+		_ = ctx.Error(cadence_errors.New(cadence_errors.Forbidden, "health.details_disabled", "detailed health report is disabled"))
+		return
+	}
+	token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(r.adminToken)) != 1 {
+		_ = ctx.Error(cadence_errors.New(cadence_errors.Unauthorized, "health.admin_token_required", "admin token required"))
+		return
+	}
+
+	report := r.Run(ctx.Request.Context())
+	ctx.JSON(statusCode(report), report)
+}
+
+func statusCode(report Report) int {
+	if report.Status == StatusUp {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// Operations documents the health routes for the OpenAPI spec.
+func (r *Registry) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:  http.MethodGet,
+			Path:    "/healthz",
+			Summary: "Liveness probe",
+			Tags:    []string{"health"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "The process is alive", Body: Report{}},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/readyz",
+			Summary: "Readiness probe",
+			Tags:    []string{"health"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:                 {Description: "Every dependency is reachable", Body: Report{}},
+				http.StatusServiceUnavailable: {Description: "A dependency is unreachable", Body: Report{}},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/healthz/details",
+			Summary: "Result of every dependency check, requires the admin bearer token",
+			Tags:    []string{"health"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:                 {Description: "Every dependency is reachable", Body: Report{}},
+				http.StatusServiceUnavailable: {Description: "A dependency is unreachable", Body: Report{}},
+			},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/health"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		router *gin.Engine
+		target *health.Registry
+		mongo  error
+	)
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	BeforeEach(func() {
+		mongo = nil
+		target = health.New(time.Second, "secret")
+		target.Register(health.CheckerFunc("mongo", func(ctx context.Context) error { return mongo }))
+
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+		target.RegisterRoutes(router)
+	})
+
+	It("is always live", func() {
+		mongo = errors.New("unreachable")
+		Expect(get("/healthz", "").Code).To(Equal(200))
+	})
+
+	It("is ready when every check passes", func() {
+		Expect(get("/readyz", "").Code).To(Equal(200))
+	})
+
+	It("is not ready when a check fails, without exposing the failure", func() {
+		mongo = errors.New("connection refused to 10.0.0.1")
+		w := get("/readyz", "")
+		Expect(w.Code).To(Equal(503))
+		Expect(w.Body.String()).NotTo(ContainSubstring("10.0.0.1"))
+	})
+
+	It("is not ready while draining", func() {
+		target.Drain()
+		Expect(get("/readyz", "").Code).To(Equal(503))
+	})
+
+	It("fails a check that exceeds its timeout", func() {
+		target.RegisterWithTimeout(health.CheckerFunc("queue", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}), 10*time.Millisecond)
+
+		report := target.Run(context.TODO())
+		Expect(report.Status).To(Equal(health.StatusDown))
+		Expect(report.Checks).To(ContainElement(And(
+			HaveField("Name", "queue"),
+			HaveField("Error", context.DeadlineExceeded.Error()),
+		)))
+	})
+
+	Context("the detailed report", func() {
+		It("requires the admin token", func() {
+			Expect(get("/healthz/details", "").Code).To(Equal(401))
+			Expect(get("/healthz/details", "wrong").Code).To(Equal(401))
+		})
+
+		It("requires the token to be sent as a bearer token", func() {
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/healthz/details", nil)
+			request.Header.Set("Authorization", "secret")
+			router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(401))
+		})
+
+		It("lists every check for admins", func() {
+			mongo = errors.New("unreachable")
+			w := get("/healthz/details", "secret")
+			Expect(w.Code).To(Equal(503))
+
+			var report health.Report
+			Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(Succeed())
+			Expect(report.Checks).To(ConsistOf(And(HaveField("Name", "mongo"), HaveField("Error", "unreachable"))))
+		})
+
+		It("is disabled without an admin token", func() {
+			target = health.New(time.Second, "")
+			router = gin.New()
+			router.Use(middleware.ErrorHandler())
+			target.RegisterRoutes(router)
+			Expect(get("/healthz/details", "").Code).To(Equal(403))
+		})
+	})
+})
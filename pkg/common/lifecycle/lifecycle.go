@@ -0,0 +1,134 @@
+// Package lifecycle starts and stops the application's subsystems in a predictable order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Hook is a subsystem that needs to be started and stopped. Either function may be nil.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Lifecycle is a registry of hooks. Hooks are started in the order they were appended and stopped in reverse, so a
+// subsystem can rely on everything registered before it while it runs.
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+	failed  chan error
+}
+
+func New() *Lifecycle {
+	return &Lifecycle{failed: make(chan error, 1)}
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// AppendWorker registers a background worker. run is called in its own goroutine once the lifecycle starts and its
+// context is cancelled on stop; stop waits for run to return. A worker that returns an error while the application
+// is running is reported through Failed.
+func (l *Lifecycle) AppendWorker(name string, run func(ctx context.Context) error) {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+	l.Append(Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				if err := run(ctx); err != nil && ctx.Err() == nil {
+					l.Fail(fmt.Errorf("worker %s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("worker %s did not stop: %w", name, ctx.Err())
+			}
+		},
+	})
+}
+
+// Start runs every start hook in order. If one fails, the hooks that already started are stopped and the error is
+// returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks[l.started:]
+	l.mu.Unlock()
+
+	for _, hook := range hooks {
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				startErr := fmt.Errorf("failed to start %s: %w", hook.Name, err)
+				if stopErr := l.Stop(ctx); stopErr != nil {
+					return fmt.Errorf("%w (rollback: %s)", startErr, stopErr.Error())
+				}
+				return startErr
+			}
+		}
+		l.mu.Lock()
+		l.started++
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop runs the stop hooks of every started hook in reverse order. Every hook is given the chance to stop even if an
+// earlier one fails; all failures are returned together.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var problems []string
+	for {
+		l.mu.Lock()
+		if l.started == 0 {
+			l.mu.Unlock()
+			break
+		}
+		l.started--
+		hook := l.hooks[l.started]
+		l.mu.Unlock()
+
+		if hook.Stop == nil {
+			continue
+		}
+		if err := hook.Stop(ctx); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", hook.Name, err.Error()))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("failed to stop " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Fail reports that a running subsystem failed. Only the first failure is kept.
+func (l *Lifecycle) Fail(err error) {
+	select {
+	case l.failed <- err:
+	default:
+	}
+}
+
+// Failed receives the first failure reported by a running subsystem.
+func (l *Lifecycle) Failed() <-chan error {
+	return l.failed
+}
@@ -0,0 +1,92 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lifecycle", func() {
+	var (
+		target *lifecycle.Lifecycle
+		calls  []string
+		ctx    context.Context
+	)
+
+	hook := func(name string, startErr error) lifecycle.Hook {
+		return lifecycle.Hook{
+			Name: name,
+			Start: func(context.Context) error {
+				calls = append(calls, "start "+name)
+				return startErr
+			},
+			Stop: func(context.Context) error {
+				calls = append(calls, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		target = lifecycle.New()
+		calls = nil
+		ctx = context.TODO()
+	})
+
+	It("starts hooks in order and stops them in reverse", func() {
+		target.Append(hook("db", nil))
+		target.Append(hook("server", nil))
+
+		Expect(target.Start(ctx)).To(Succeed())
+		Expect(target.Stop(ctx)).To(Succeed())
+		Expect(calls).To(Equal([]string{"start db", "start server", "stop server", "stop db"}))
+	})
+
+	It("stops the hooks that started when a later one fails", func() {
+		target.Append(hook("db", nil))
+		target.Append(hook("server", errors.New("address in use")))
+		target.Append(hook("worker", nil))
+
+		Expect(target.Start(ctx)).To(MatchError(ContainSubstring("failed to start server")))
+		Expect(calls).To(Equal([]string{"start db", "start server", "stop db"}))
+	})
+
+	It("reports every stop failure", func() {
+		target.Append(lifecycle.Hook{Name: "a", Stop: func(context.Context) error { return errors.New("boom") }})
+		target.Append(lifecycle.Hook{Name: "b", Stop: func(context.Context) error { return errors.New("bang") }})
+
+		Expect(target.Start(ctx)).To(Succeed())
+		err := target.Stop(ctx)
+		Expect(err).To(MatchError(ContainSubstring("a: boom")))
+		Expect(err).To(MatchError(ContainSubstring("b: bang")))
+	})
+
+	Context("background workers", func() {
+		It("cancels the worker on stop and waits for it", func() {
+			stopped := false
+			target.AppendWorker("purge", func(ctx context.Context) error {
+				<-ctx.Done()
+				stopped = true
+				return ctx.Err()
+			})
+
+			Expect(target.Start(ctx)).To(Succeed())
+			Expect(target.Stop(ctx)).To(Succeed())
+			Expect(stopped).To(BeTrue())
+			Expect(target.Failed()).NotTo(Receive())
+		})
+
+		It("reports a worker that fails while running", func() {
+			target.AppendWorker("purge", func(ctx context.Context) error {
+				return errors.New("boom")
+			})
+
+			Expect(target.Start(ctx)).To(Succeed())
+			Eventually(target.Failed()).Should(Receive(MatchError(ContainSubstring("worker purge: boom"))))
+			Expect(target.Stop(ctx)).To(Succeed())
+		})
+	})
+})
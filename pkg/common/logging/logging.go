@@ -0,0 +1,95 @@
+// Package logging builds the structured logger shared by every layer. Records carry the request and user ids found
+// on their context, and values that look like credentials or email addresses are redacted before they are written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New creates a logger writing to w. level is one of debug, info, warn or error and format is json or text.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or text", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Discard returns a logger that drops every record, for tests.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// contextHandler adds the request scoped ids from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := reqctx.RequestId(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if id := reqctx.UserId(ctx); id != "" {
+			r.AddAttrs(slog.String("user_id", id))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+const redacted = "[REDACTED]"
+
+var secretKeys = []string{"token", "password", "secret", "authorization", "cookie", "api_key", "apikey"}
+
+// redact hides the values of attributes whose key names a credential, and masks email addresses wherever they
+// appear in string values, keeping only the domain.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if masked := MaskEmails(a.Value.String()); masked != a.Value.String() {
+			return slog.String(a.Key, masked)
+		}
+	case slog.KindAny:
+		// errors often embed the input that caused them
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,68 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var (
+		buf    *bytes.Buffer
+		logger *slog.Logger
+	)
+
+	record := func() map[string]interface{} {
+		var r map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &r)).To(Succeed())
+		return r
+	}
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		var err error
+		logger, err = logging.New(buf, "info", logging.FormatJSON)
+		Expect(err).To(BeNil())
+	})
+
+	It("attaches the request and user ids from the context", func() {
+		ctx := reqctx.WithUserId(reqctx.WithRequestId(context.TODO(), "req-1"), "user-1")
+		logger.InfoContext(ctx, "hello")
+
+		Expect(record()).To(And(HaveKeyWithValue("request_id", "req-1"), HaveKeyWithValue("user_id", "user-1")))
+	})
+
+	It("redacts credentials and email addresses", func() {
+		logger.Info("hello",
+			slog.String("access_token", "abc"),
+			slog.String("Authorization", "Bearer abc"),
+			slog.String("email", "jo@example.com"),
+			slog.Any("error", errors.New("failed to get user with email jo@example.com")),
+		)
+
+		r := record()
+		Expect(r).To(HaveKeyWithValue("access_token", "[REDACTED]"))
+		Expect(r).To(HaveKeyWithValue("Authorization", "[REDACTED]"))
+		Expect(r).To(HaveKeyWithValue("email", "***@example.com"))
+		Expect(r).To(HaveKeyWithValue("error", "failed to get user with email ***@example.com"))
+	})
+
+	It("drops records below the configured level", func() {
+		logger.Debug("hello")
+		Expect(buf.Len()).To(BeZero())
+	})
+
+	It("rejects unknown levels and formats", func() {
+		_, err := logging.New(buf, "loud", logging.FormatJSON)
+		Expect(err).NotTo(BeNil())
+		_, err = logging.New(buf, "info", "xml")
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,10 @@
+package logging
+
+import "regexp"
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// MaskEmails replaces the local part of every email address in s, e.g. "jo@example.com" becomes "***@example.com".
+func MaskEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, "***@$1")
+}
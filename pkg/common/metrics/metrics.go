@@ -0,0 +1,174 @@
+// Package metrics defines every Prometheus metric the API exposes and the hooks that record them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cadence"
+
+// Metrics owns a registry and the collectors registered on it. A nil *Metrics is valid and records nothing, which
+// keeps services usable in tests without wiring metrics; only serving it with RegisterRoutes needs one from New.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	mongoDuration *prometheus.HistogramVec
+	mongoErrors   *prometheus.CounterVec
+
+	usersCreated     prometheus.Counter
+	habitsCreated    prometheus.Counter
+	checkInsRecorded prometheus.Counter
+	streaksBroken    prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "mongo",
+			Name:      "command_duration_seconds",
+			Help:      "Mongo command latency by collection and command.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"collection", "command"}),
+		mongoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "mongo",
+			Name:      "command_errors_total",
+			Help:      "Failed Mongo commands by collection and command.",
+		}, []string{"collection", "command"}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Users created.",
+		}),
+		habitsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "habits_created_total",
+			Help:      "Habits created.",
+		}),
+		checkInsRecorded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_ins_recorded_total",
+			Help:      "Habit check-ins recorded.",
+		}),
+		streaksBroken: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "streaks_broken_total",
+			Help:      "Habit streaks that were reset because a scheduled day was missed.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.mongoDuration,
+		m.mongoErrors,
+		m.usersCreated,
+		m.habitsCreated,
+		m.checkInsRecorded,
+		m.streaksBroken,
+	)
+	return m
+}
+
+// Registry exposes the registry so that other subsystems can register their own collectors.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Middleware records the count and latency of every request, labelled by the route template rather than the raw
+// path to keep cardinality bounded.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	if m == nil {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": ctx.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(ctx.Writer.Status()),
+		}
+		m.httpRequests.With(labels).Inc()
+		m.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) UserCreated() {
+	if m != nil {
+		m.usersCreated.Inc()
+	}
+}
+
+func (m *Metrics) HabitCreated() {
+	if m != nil {
+		m.habitsCreated.Inc()
+	}
+}
+
+func (m *Metrics) CheckInRecorded() {
+	if m != nil {
+		m.checkInsRecorded.Inc()
+	}
+}
+
+func (m *Metrics) StreakBroken() {
+	if m != nil {
+		m.streaksBroken.Inc()
+	}
+}
+
+// RegisterRoutes serves the registry in the Prometheus text format at /metrics.
+func (m *Metrics) RegisterRoutes(router gin.IRouter) {
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})))
+}
+
+// Operations documents the metrics route for the OpenAPI spec.
+func (m *Metrics) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:  http.MethodGet,
+			Path:    "/metrics",
+			Summary: "Prometheus metrics",
+			Tags:    []string{"metrics"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "Metrics in the Prometheus text exposition format"},
+			},
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/metrics"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var _ = Describe("Metrics", func() {
+	var (
+		target *metrics.Metrics
+		router *gin.Engine
+	)
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/metrics", nil)
+		router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(200))
+		return w.Body.String()
+	}
+
+	BeforeEach(func() {
+		target = metrics.New()
+		router = gin.New()
+		router.Use(target.Middleware())
+		target.RegisterRoutes(router)
+		router.GET("/user/:email", func(ctx *gin.Context) { ctx.Status(http.StatusNotFound) })
+	})
+
+	It("labels requests by route template", func() {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/user/jo@example.com", nil)
+		router.ServeHTTP(w, request)
+
+		Expect(scrape()).To(ContainSubstring(
+			`cadence_http_requests_total{method="GET",route="/user/:email",status="404"} 1`,
+		))
+	})
+
+	It("records mongo commands per collection", func() {
+		monitor := target.CommandMonitor()
+		command, _ := bson.Marshal(bson.D{{Key: "find", Value: "users"}})
+		monitor.Started(context.TODO(), &event.CommandStartedEvent{
+			Command:      command,
+			DatabaseName: "golangAPI",
+			CommandName:  "find",
+			RequestID:    1,
+		})
+		monitor.Failed(context.TODO(), &event.CommandFailedEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, DurationNanos: 1000},
+			Failure:              "boom",
+		})
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`cadence_mongo_command_duration_seconds_count{collection="users",command="find"} 1`))
+		Expect(body).To(ContainSubstring(`cadence_mongo_command_errors_total{collection="users",command="find"} 1`))
+	})
+
+	It("counts domain events and tolerates a nil receiver", func() {
+		target.UserCreated()
+		target.StreakBroken()
+		var unset *metrics.Metrics
+		unset.UserCreated()
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("cadence_users_created_total 1"))
+		Expect(body).To(ContainSubstring("cadence_streaks_broken_total 1"))
+	})
+	It("hands out no-op hooks from a nil receiver", func() {
+		var unset *metrics.Metrics
+		router := gin.New()
+		router.Use(unset.Middleware())
+		router.GET("/ping", func(ctx *gin.Context) {
+			ctx.Status(http.StatusNoContent)
+		})
+		request, _ := http.NewRequest("GET", "/ping", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+
+		monitor := unset.CommandMonitor()
+		Expect(monitor).NotTo(BeNil())
+		Expect(monitor.Started).To(BeNil())
+	})
+})
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandMonitor returns a driver monitor recording the latency and failures of every Mongo command.
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	if m == nil {
+		return &event.CommandMonitor{}
+	}
+	// the collection is only present on the started event, so remember it until the command finishes
+	var inflight sync.Map
+
+	finish := func(requestId int64, commandName string, duration time.Duration, failed bool) {
+		collection := "unknown"
+		if c, ok := inflight.LoadAndDelete(requestId); ok {
+			collection = c.(string)
+		}
+		m.mongoDuration.WithLabelValues(collection, commandName).Observe(duration.Seconds())
+		if failed {
+			m.mongoErrors.WithLabelValues(collection, commandName).Inc()
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			inflight.Store(e.RequestID, mongodb.CollectionOf(e))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, e.CommandName, time.Duration(e.DurationNanos), false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.CommandName, time.Duration(e.DurationNanos), true)
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSPolicy lists what cross-origin browser clients may do. An origin is either "*", an exact origin such as
+// "https://app.example.com", or a subdomain wildcard such as "https://*.example.com".
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+func (p CORSPolicy) allows(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		if scheme, host, ok := strings.Cut(allowed, "://*."); ok {
+			if rest, found := strings.CutPrefix(origin, scheme+"://"); found && strings.HasSuffix(rest, "."+host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CORS answers preflight requests and adds the CORS headers to responses for allowed origins. Requests from other
+// origins are served without them, which leaves browsers to block the response; their preflights are refused.
+func CORS(policy CORSPolicy) gin.HandlerFunc {
+	allowMethods := strings.Join(policy.AllowedMethods, ", ")
+	allowHeaders := strings.Join(policy.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(policy.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+	// a wildcard cannot be combined with credentials, so the origin is echoed back instead
+	wildcard := !policy.AllowCredentials && len(policy.AllowedOrigins) == 1 && policy.AllowedOrigins[0] == "*"
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+		header := ctx.Writer.Header()
+		header.Add("Vary", "Origin")
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+
+		if !policy.allows(origin) {
+			if preflight {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		if wildcard {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			if policy.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			ctx.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CORS", func() {
+	var (
+		policy  middleware.CORSPolicy
+		request *http.Request
+		w       *httptest.ResponseRecorder
+		served  bool
+	)
+
+	BeforeEach(func() {
+		policy = middleware.CORSPolicy{
+			AllowedOrigins: []string{"https://app.example.com", "https://*.preview.example.com"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		}
+		request, _ = http.NewRequest("GET", "/user", nil)
+		served = false
+	})
+
+	JustBeforeEach(func() {
+		router := gin.New()
+		router.Use(middleware.CORS(policy))
+		router.GET("/user", func(ctx *gin.Context) {
+			served = true
+			ctx.Status(http.StatusOK)
+		})
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+	})
+
+	Context("an allowed origin makes a request", func() {
+		BeforeEach(func() {
+			request.Header.Set("Origin", "https://app.example.com")
+		})
+		It("allows the origin and exposes headers", func() {
+			Expect(served).To(BeTrue())
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://app.example.com"))
+			Expect(w.Header().Get("Access-Control-Expose-Headers")).To(Equal("X-Request-ID"))
+			Expect(w.Header().Values("Vary")).To(ContainElement("Origin"))
+		})
+	})
+
+	Context("a preview subdomain sends a preflight", func() {
+		BeforeEach(func() {
+			request, _ = http.NewRequest("OPTIONS", "/user", nil)
+			request.Header.Set("Origin", "https://pr-12.preview.example.com")
+			request.Header.Set("Access-Control-Request-Method", "POST")
+		})
+		It("answers it without reaching the routes", func() {
+			Expect(served).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, POST"))
+			Expect(w.Header().Get("Access-Control-Allow-Headers")).To(Equal("Content-Type, X-Request-ID"))
+			Expect(w.Header().Get("Access-Control-Max-Age")).To(Equal("600"))
+		})
+	})
+
+	Context("another origin sends a preflight", func() {
+		BeforeEach(func() {
+			request, _ = http.NewRequest("OPTIONS", "/user", nil)
+			request.Header.Set("Origin", "https://evil.example.org")
+			request.Header.Set("Access-Control-Request-Method", "POST")
+		})
+		It("is refused", func() {
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+	})
+
+	Context("every origin is allowed", func() {
+		BeforeEach(func() {
+			policy.AllowedOrigins = []string{"*"}
+			request.Header.Set("Origin", "https://anyone.example.org")
+		})
+		It("responds with a wildcard", func() {
+			Expect(w.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/problem"
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// ErrorHandler renders the last error attached to the gin context with ctx.Error. Controllers only need to attach the
+// error and return; the response status is derived from the error's cadence_errors.Kind.
+//
+// Clients that list application/problem+json in their Accept header receive an RFC 7807 document. Routes marked with
+// LegacyErrors answer everyone else with the status/message/data shape old clients parse, and the remaining routes
+// with the response.Envelope used by successful responses.
+func ErrorHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+		status := HTTPStatus(cadence_errors.KindOf(err))
+		if problem.Accepted(ctx.GetHeader("Accept")) {
+			// render.JSON only sets a content type when none is present, so ours must be set first
+			ctx.Header("Content-Type", problem.ContentType)
+			details := problem.FromError(err, status, ctx.Request.URL.Path)
+			details.RequestId = reqctx.RequestId(ctx.Request.Context())
+			ctx.Render(status, render.JSON{Data: details})
+			return
+		}
+		if IsLegacy(ctx) {
+			renderLegacyError(ctx, status, err)
+			return
+		}
+		renderEnvelopeError(ctx, status, err)
+	}
+}
+
+const legacyErrorsKey = "middleware.legacy_errors"
+
+// LegacyErrors makes ErrorHandler render errors on the routes it is installed on as a response.Legacy, the shape of
+// the unversioned routes. Controllers render successes in the same shape when IsLegacy reports the route as legacy.
+func LegacyErrors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(legacyErrorsKey, true)
+		ctx.Next()
+	}
+}
+
+// IsLegacy reports whether the route being served answers with response.Legacy bodies.
+func IsLegacy(ctx *gin.Context) bool {
+	return ctx.GetBool(legacyErrorsKey)
+}
+
+func renderLegacyError(ctx *gin.Context, status int, err error) {
+	body := response.Legacy[string]{
+		Status:  status,
+		Message: response.MessageError,
+		Data:    response.LegacyData[string]{Data: "an unexpected error occurred"},
+	}
+	if e, ok := cadence_errors.As(err); ok && e.Kind != cadence_errors.Internal {
+		body.Data.Data = e.Message
+		body.Data.Code = e.Code
+		body.Data.Details = e.Details
+	}
+	ctx.JSON(status, body)
+}
+
+func renderEnvelopeError(ctx *gin.Context, status int, err error) {
+	body := response.ErrorBody{Message: "an unexpected error occurred"}
+	if e, ok := cadence_errors.As(err); ok && e.Kind != cadence_errors.Internal {
+		body.Code = e.Code
+		body.Message = e.Message
+		body.Details = e.Details
+		body.Fields = e.Fields
+	}
+	ctx.JSON(status, response.Failure(ctx.Request.Context(), status, body))
+}
+
+// HTTPStatus maps an error kind to the HTTP status code returned to clients.
+func HTTPStatus(kind cadence_errors.Kind) int {
+	switch kind {
+	case cadence_errors.NotFound:
+		return http.StatusNotFound
+	case cadence_errors.Validation:
+		return http.StatusBadRequest
+	case cadence_errors.Conflict:
+		return http.StatusConflict
+	case cadence_errors.Unauthorized:
+		return http.StatusUnauthorized
+	case cadence_errors.Forbidden:
+		return http.StatusForbidden
+	case cadence_errors.RateLimited:
+		return http.StatusTooManyRequests
+	case cadence_errors.PreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
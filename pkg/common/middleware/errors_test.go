@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorHandler", func() {
+	var (
+		router *gin.Engine
+		err    error
+	)
+
+	BeforeEach(func() {
+		err = cadence_errors.New(cadence_errors.NotFound, "user.not_found", "user not found").WithDetail("email", "jo@example.com")
+	})
+
+	JustBeforeEach(func() {
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+		fail := func(ctx *gin.Context) { _ = ctx.Error(err) }
+		router.GET("/v1/user", fail)
+		router.Group("", middleware.LegacyErrors()).GET("/user", fail)
+	})
+
+	send := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, request)
+		var body map[string]interface{}
+		Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+		return w.Code, body
+	}
+
+	It("renders the envelope", func() {
+		status, body := send("/v1/user")
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(body).To(HaveKeyWithValue("error", HaveKeyWithValue("code", "user.not_found")))
+	})
+
+	Context("the route keeps legacy errors", func() {
+		It("renders the status, message and nested data", func() {
+			status, body := send("/user")
+			Expect(status).To(Equal(http.StatusNotFound))
+			Expect(body).To(Equal(map[string]interface{}{
+				"status":  float64(http.StatusNotFound),
+				"message": "error",
+				"data": map[string]interface{}{
+					"data":    "user not found",
+					"code":    "user.not_found",
+					"details": map[string]interface{}{"email": "jo@example.com"},
+				},
+			}))
+		})
+
+		Context("the error is internal", func() {
+			BeforeEach(func() {
+				err = cadence_errors.Wrap(http.ErrHandlerTimeout, cadence_errors.Internal, "", "failed to query users")
+			})
+			It("hides what went wrong", func() {
+				_, body := send("/user")
+				Expect(body).To(HaveKeyWithValue("data", map[string]interface{}{"data": "an unexpected error occurred"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger writes one structured record per request. It replaces gin's default text logger; the route is the
+// template ("/v1/user/:email") rather than the raw path so that records can be grouped.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("error", ctx.Errors.Last().Err))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
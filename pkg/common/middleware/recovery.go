@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panicking handler into an internal error, logging the panic along with its stack. It must run
+// after RequestLogger, the metrics middleware and ErrorHandler so that they record and render the failure like any
+// other 500.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			logger.ErrorContext(ctx, "handler panicked",
+				slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())),
+			)
+			_ = ctx.Error(cadence_errors.Wrap(fmt.Errorf("panic: %v", recovered), cadence_errors.Internal, "", "handler panicked"))
+			ctx.Abort()
+		}()
+		ctx.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recovery", func() {
+	It("answers a panic with the error envelope and logs it with the request", func() {
+		logs := &bytes.Buffer{}
+		logger, err := logging.New(logs, "info", logging.FormatJSON)
+		Expect(err).To(BeNil())
+
+		router := gin.New()
+		router.Use(middleware.RequestLogger(logger), middleware.ErrorHandler(), middleware.Recovery(logger))
+		router.GET("/boom", func(*gin.Context) { panic("boom") })
+
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/boom", nil)
+		router.ServeHTTP(w, request)
+
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		Expect(w.Body.String()).To(ContainSubstring("an unexpected error occurred"))
+		Expect(logs.String()).To(ContainSubstring(`"msg":"handler panicked"`))
+		Expect(logs.String()).To(ContainSubstring(`"msg":"request"`))
+		Expect(logs.String()).To(ContainSubstring(`"status":500`))
+		Expect(logs.String()).To(ContainSubstring("panic: boom"))
+	})
+})
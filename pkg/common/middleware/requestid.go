@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// validRequestId bounds what we accept from clients, since the id is echoed back and written to logs.
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestId accepts the client's X-Request-ID or generates one, stores it on the request context (see reqctx) and
+// echoes it in the response headers. The engine needs ContextWithFallback so that handlers passing *gin.Context on
+// to services expose it.
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(reqctx.RequestIdHeader)
+		if !validRequestId.MatchString(id) {
+			id = reqctx.NewRequestId()
+		}
+
+		ctx.Request = ctx.Request.WithContext(reqctx.WithRequestId(ctx.Request.Context(), id))
+		ctx.Header(reqctx.RequestIdHeader, id)
+		ctx.Next()
+	}
+}
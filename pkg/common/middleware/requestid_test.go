@@ -0,0 +1,97 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequestId", func() {
+	var (
+		router  *gin.Engine
+		w       *httptest.ResponseRecorder
+		header  string
+		seen    string
+		handler gin.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		header = ""
+		seen = ""
+		handler = func(ctx *gin.Context) {
+			// services receive the gin context, so read it the way they would
+			var serviceCtx context.Context = ctx
+			seen = reqctx.RequestId(serviceCtx)
+			ctx.Status(http.StatusNoContent)
+		}
+	})
+
+	JustBeforeEach(func() {
+		router = gin.New()
+		router.ContextWithFallback = true
+		router.Use(middleware.RequestId(), middleware.ErrorHandler())
+		router.GET("/", handler)
+
+		w = httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/", nil)
+		request.Header.Set("X-Request-ID", header)
+		router.ServeHTTP(w, request)
+	})
+
+	Context("the client sends an id", func() {
+		BeforeEach(func() {
+			header = "client-id-1"
+		})
+		It("is used for the request and echoed back", func() {
+			Expect(seen).To(Equal("client-id-1"))
+			Expect(w.Header().Get("X-Request-ID")).To(Equal("client-id-1"))
+		})
+	})
+
+	Context("the client sends an unusable id", func() {
+		BeforeEach(func() {
+			header = "<script>"
+		})
+		It("is replaced with a generated id", func() {
+			Expect(seen).To(MatchRegexp("^[0-9a-f]{32}$"))
+			Expect(w.Header().Get("X-Request-ID")).To(Equal(seen))
+		})
+	})
+
+	Context("the request fails", func() {
+		BeforeEach(func() {
+			header = "client-id-2"
+			handler = func(ctx *gin.Context) {
+				_ = ctx.Error(cadence_errors.ErrNotFound)
+			}
+		})
+		It("includes the id in the error envelope", func() {
+			var body map[string]interface{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("request_id", "client-id-2"))
+		})
+	})
+
+	Context("the request succeeds", func() {
+		BeforeEach(func() {
+			header = "client-id-3"
+			handler = func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, "ok"))
+			}
+		})
+		It("includes the id in the envelope", func() {
+			var body map[string]interface{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("request_id", "client-id-3"))
+		})
+	})
+})
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecurityHeaders", func() {
+	var (
+		request *http.Request
+		w       *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		request, _ = http.NewRequest("GET", "/", nil)
+	})
+
+	JustBeforeEach(func() {
+		router := gin.New()
+		router.Use(middleware.SecurityHeaders(middleware.SecurityPolicy{
+			HSTSMaxAge:            24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			ContentSecurityPolicy: "default-src 'none'",
+			FrameOptions:          "DENY",
+			TrustedProxies:        []string{"10.0.0.0/8"},
+		}))
+		router.GET("/", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+	})
+
+	It("sets the configured headers", func() {
+		Expect(w.Header().Get("Content-Security-Policy")).To(Equal("default-src 'none'"))
+		Expect(w.Header().Get("X-Frame-Options")).To(Equal("DENY"))
+		Expect(w.Header().Get("X-Content-Type-Options")).To(Equal("nosniff"))
+		Expect(w.Header().Get("Strict-Transport-Security")).To(BeEmpty())
+	})
+
+	Context("the request was made over TLS", func() {
+		BeforeEach(func() {
+			request.TLS = &tls.ConnectionState{}
+		})
+		It("sets HSTS", func() {
+			Expect(w.Header().Get("Strict-Transport-Security")).To(Equal("max-age=86400; includeSubDomains"))
+		})
+	})
+
+	Context("a trusted proxy terminated TLS", func() {
+		BeforeEach(func() {
+			request.RemoteAddr = "10.1.2.3:4567"
+			request.Header.Set("X-Forwarded-Proto", "https")
+		})
+		It("sets HSTS", func() {
+			Expect(w.Header().Get("Strict-Transport-Security")).To(Equal("max-age=86400; includeSubDomains"))
+		})
+	})
+
+	Context("an untrusted client claims HTTPS", func() {
+		BeforeEach(func() {
+			request.RemoteAddr = "203.0.113.7:4567"
+			request.Header.Set("X-Forwarded-Proto", "https")
+		})
+		It("does not set HSTS", func() {
+			Expect(w.Header().Get("Strict-Transport-Security")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserId stores the user a request is about on the request context (see reqctx), so that every log line written
+// while serving it carries user_id. The user is read from the userId route parameter or the user_id query parameter;
+// handlers that only learn the user from what they load, such as a lookup by email, call SetUserId instead.
+func UserId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		raw := ctx.Param("userId")
+		if raw == "" {
+			raw = ctx.Query("user_id")
+		}
+		if userId, err := primitive.ObjectIDFromHex(raw); err == nil {
+			SetUserId(ctx, userId)
+		}
+		ctx.Next()
+	}
+}
+
+// SetUserId stores userId on the request context for the rest of the request.
+func SetUserId(ctx *gin.Context, userId primitive.ObjectID) {
+	ctx.Request = ctx.Request.WithContext(reqctx.WithUserId(ctx.Request.Context(), userId.Hex()))
+}
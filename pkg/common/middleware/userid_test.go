@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ = Describe("UserId", func() {
+	var (
+		router *gin.Engine
+		logs   *bytes.Buffer
+		userId primitive.ObjectID
+	)
+
+	BeforeEach(func() {
+		logs = &bytes.Buffer{}
+		logger, err := logging.New(logs, "info", logging.FormatJSON)
+		Expect(err).To(BeNil())
+		userId = primitive.NewObjectID()
+
+		router = gin.New()
+		router.ContextWithFallback = true
+		router.Use(middleware.RequestLogger(logger), middleware.UserId())
+		ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+		router.GET("/user/:userId", ok)
+		router.GET("/habits", ok)
+		router.GET("/user/by-email/:email", func(ctx *gin.Context) {
+			middleware.SetUserId(ctx, userId)
+			ctx.Status(http.StatusOK)
+		})
+	})
+
+	logged := func(path string) map[string]interface{} {
+		request, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+		var record map[string]interface{}
+		Expect(json.Unmarshal(logs.Bytes(), &record)).To(Succeed())
+		return record
+	}
+
+	It("logs the user named by the route", func() {
+		Expect(logged("/user/" + userId.Hex())).To(HaveKeyWithValue("user_id", userId.Hex()))
+	})
+
+	It("logs the user named by the query", func() {
+		Expect(logged("/habits?user_id=" + userId.Hex())).To(HaveKeyWithValue("user_id", userId.Hex()))
+	})
+
+	It("logs the user a handler resolved", func() {
+		Expect(logged("/user/by-email/jo@example.com")).To(HaveKeyWithValue("user_id", userId.Hex()))
+	})
+
+	It("ignores malformed ids", func() {
+		Expect(logged("/habits?user_id=nope")).NotTo(HaveKey("user_id"))
+	})
+})
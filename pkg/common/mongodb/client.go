@@ -0,0 +1,44 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewClient creates a client for uri without connecting it. Collections can be obtained from the client straight
+// away, which lets repositories be built before the application starts. monitor may be nil.
+func NewClient(uri string, monitor *event.CommandMonitor) (*mongo.Client, error) {
+	client, err := mongo.NewClient(options.Client().ApplyURI(uri).SetMonitor(monitor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mongo client: %w", err)
+	}
+	return client, nil
+}
+
+// Hook connects the client and pings the server when the lifecycle starts, giving up after connectTimeout, and
+// disconnects it when the lifecycle stops.
+func Hook(client *mongo.Client, connectTimeout time.Duration) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "mongo",
+		Start: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+			defer cancel()
+
+			if err := client.Connect(ctx); err != nil {
+				return fmt.Errorf("failed to connect to mongo: %w", err)
+			}
+			if err := client.Ping(ctx, nil); err != nil {
+				_ = client.Disconnect(context.Background())
+				return fmt.Errorf("failed to ping mongo: %w", err)
+			}
+			return nil
+		},
+		Stop: client.Disconnect,
+	}
+}
@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var translator = storage.Translator{Engine: "mongo", Container: "collection", Classify: classify}
+
+func classify(err error) storage.Failure {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return storage.Missing
+	case mongo.IsDuplicateKeyError(err):
+		return storage.Duplicate
+	case mongo.IsTimeout(err):
+		return storage.TimedOut
+	default:
+		return storage.Failed
+	}
+}
+
+// TranslateError converts a mongo driver error into a cadence_errors.Error so that callers above the repository layer
+// never need to know which storage engine they are talking to. A nil error is returned unchanged.
+func TranslateError(err error) error {
+	return translator.TranslateError(err)
+}
+
+// TranslateAndLog translates err like TranslateError and logs the failures that are hidden from clients.
+func TranslateAndLog(ctx context.Context, logger *slog.Logger, collection, operation string, err error) error {
+	return translator.TranslateAndLog(ctx, logger, collection, operation, err)
+}
+
+// MissingOrStale explains why a conditional update of the document matching filter, which leaves the version out,
+// matched nothing: it returns cadence_errors.ErrNotFound when the document does not exist and
+// cadence_errors.ErrStaleVersion when it does.
+func MissingOrStale(ctx context.Context, collection *mongo.Collection, filter bson.D) error {
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	switch {
+	case err != nil:
+		return TranslateError(err)
+	case count == 0:
+		return cadence_errors.ErrNotFound
+	default:
+		return cadence_errors.ErrStaleVersion
+	}
+}
@@ -0,0 +1,8 @@
+package mongodb
+
+import "time"
+
+// SetLockTTL shortens the migration lock's TTL so that specs can outlast it.
+func (m *Migrator) SetLockTTL(ttl time.Duration) {
+	m.lockTTL = ttl
+}
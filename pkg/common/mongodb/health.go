@@ -0,0 +1,16 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/health"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// HealthChecker pings the primary.
+func HealthChecker(client *mongo.Client) health.Checker {
+	return health.CheckerFunc("mongo", func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+}
@@ -0,0 +1,287 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// migrationsCollection records which migrations have been applied, keyed by version.
+	migrationsCollection = "migrations"
+	// migrationLocksCollection holds the single document that a runner owns while it migrates.
+	migrationLocksCollection = "migration_locks"
+	migrationLockId          = "migrations"
+)
+
+var errLockLost = errors.New("lost the migration lock to another runner")
+
+// Migration changes the schema of a database, e.g. by creating an index or rewriting documents. Mongo cannot apply a
+// migration and record it atomically, so Up and Down must be safe to run again after a partial failure.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// MigrationStatus reports whether a migration has been applied. AppliedAt is zero for pending migrations.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back migrations in version order, recording them in the migrations collection. Only one
+// runner at a time holds the migration lock; others wait for it. The holder renews the lock while it migrates.
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	// lockTTL bounds how long a runner that died without releasing the lock keeps others out. Live runners renew it.
+	lockTTL      time.Duration
+	pollInterval time.Duration
+}
+
+func NewMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Migrator{
+		db:           db,
+		migrations:   sorted,
+		lockTTL:      10 * time.Minute,
+		pollInterval: 500 * time.Millisecond,
+	}
+}
+
+type migrationRecord struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Up applies every pending migration and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	ctx, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err = migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("failed to apply migration %d %s: %w", migration.Version, migration.Name, interrupted(ctx, err))
+		}
+		record := migrationRecord{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}
+		if _, err = m.db.Collection(migrationsCollection).InsertOne(ctx, record); err != nil {
+			return done, fmt.Errorf("failed to record migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the latest steps applied migrations, newest first, and returns the ones it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("expected at least one step to roll back, got %d", steps)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	ctx, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var done []Migration
+	for _, version := range versions {
+		if len(done) == steps {
+			break
+		}
+		migration, ok := m.find(version)
+		if !ok {
+			return done, fmt.Errorf("migration %d %s is applied but unknown to this build", version, applied[version].Name)
+		}
+		if err = migration.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("failed to roll back migration %d %s: %w", migration.Version, migration.Name, interrupted(ctx, err))
+		}
+		if _, err = m.db.Collection(migrationsCollection).DeleteOne(ctx, bson.D{{Key: "_id", Value: version}}); err != nil {
+			return done, fmt.Errorf("failed to unrecord migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status lists every known migration in version order, followed by any applied migration this build does not know.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	var unknown []MigrationStatus
+	for _, record := range applied {
+		unknown = append(unknown, MigrationStatus{Version: record.Version, Name: record.Name, AppliedAt: record.AppliedAt})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+	return append(statuses, unknown...), nil
+}
+
+func (m *Migrator) validate() error {
+	for i, migration := range m.migrations {
+		if migration.Up == nil || migration.Down == nil {
+			return fmt.Errorf("migration %d %s must have both up and down", migration.Version, migration.Name)
+		}
+		if i > 0 && m.migrations[i-1].Version == migration.Version {
+			return fmt.Errorf("migration version %d is used more than once", migration.Version)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]migrationRecord, error) {
+	cursor, err := m.db.Collection(migrationsCollection).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	var records []migrationRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[int]migrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// lock waits until it owns the migration lock document. A runner takes the document over once it has expired;
+// otherwise the upsert collides with the existing document's _id and the runner tries again. The lock is renewed every
+// third of its TTL until released. The returned context is cancelled once the lock is lost or renewals have failed for
+// long enough that it is about to expire, so that a runner stops migrating before another one can take the lock over.
+func (m *Migrator) lock(ctx context.Context) (context.Context, func(), error) {
+	locks := m.db.Collection(migrationLocksCollection)
+	owner := primitive.NewObjectID()
+	for {
+		now := time.Now().UTC()
+		_, err := locks.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: migrationLockId}, {Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "owner", Value: owner}, {Key: "expires_at", Value: now.Add(m.lockTTL)}}}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("failed to lock migrations: %w", ctx.Err())
+		case <-time.After(m.pollInterval):
+		}
+	}
+
+	locked, cancel := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(m.lockTTL / 3)
+		defer ticker.Stop()
+		expires := time.Now().Add(m.lockTTL)
+		for {
+			select {
+			case <-locked.Done():
+				return
+			case <-ticker.C:
+			}
+			attempted := time.Now()
+			err := m.renew(locked, owner)
+			switch {
+			case err == nil:
+				expires = attempted.Add(m.lockTTL)
+			case errors.Is(err, errLockLost) || time.Until(expires) <= m.lockTTL/3:
+				// give up while there is still time to stop before another runner can take the lock over
+				cancel(err)
+				return
+			}
+		}
+	}()
+
+	return locked, func() {
+		cancel(nil)
+		<-renewed
+		_, _ = locks.DeleteOne(context.Background(), bson.D{{Key: "_id", Value: migrationLockId}, {Key: "owner", Value: owner}})
+	}, nil
+}
+
+// renew pushes back the expiry of the lock owner holds. It fails if the lock has been taken over in the meantime.
+func (m *Migrator) renew(ctx context.Context, owner primitive.ObjectID) error {
+	result, err := m.db.Collection(migrationLocksCollection).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: migrationLockId}, {Key: "owner", Value: owner}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "expires_at", Value: time.Now().UTC().Add(m.lockTTL)}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to renew the migration lock: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return errLockLost
+	}
+	return nil
+}
+
+// interrupted returns why ctx was cancelled, such as a lost migration lock, in place of the err it caused.
+func interrupted(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb/mongotest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ = Describe("Migrator", func() {
+	var (
+		db       *mongo.Database
+		target   *mongodb.Migrator
+		versions func([]mongodb.Migration) []int
+	)
+
+	BeforeEach(func() {
+		db = mongotest.Database()
+		target = mongodb.NewMigrator(db, mongodb.Migrations)
+		versions = func(migrations []mongodb.Migration) []int {
+			var v []int
+			for _, m := range migrations {
+				v = append(v, m.Version)
+			}
+			return v
+		}
+	})
+
+	Context("Up", func() {
+		It("applies every migration once", func() {
+			applied, err := target.Up(context.TODO())
+			Expect(err).To(BeNil())
+			Expect(versions(applied)).To(Equal(versions(mongodb.Migrations)))
+
+			applied, err = target.Up(context.TODO())
+			Expect(err).To(BeNil())
+			Expect(applied).To(BeEmpty())
+		})
+		It("makes emails unique", func() {
+			_, err := target.Up(context.TODO())
+			Expect(err).To(BeNil())
+
+			users := db.Collection("users")
+			_, err = users.InsertOne(context.TODO(), bson.D{{Key: "email", Value: "test@test.com"}})
+			Expect(err).To(BeNil())
+			_, err = users.InsertOne(context.TODO(), bson.D{{Key: "email", Value: "test@test.com"}})
+			Expect(mongo.IsDuplicateKeyError(err)).To(BeTrue())
+		})
+		It("applies each migration once when runners start together", func() {
+			errs := make(chan error, 4)
+			counts := make(chan int, cap(errs))
+			for i := 0; i < cap(errs); i++ {
+				go func() {
+					applied, err := mongodb.NewMigrator(db, mongodb.Migrations).Up(context.TODO())
+					counts <- len(applied)
+					errs <- err
+				}()
+			}
+			total := 0
+			for i := 0; i < cap(errs); i++ {
+				Expect(<-errs).To(Succeed())
+				total += <-counts
+			}
+			Expect(total).To(Equal(len(mongodb.Migrations)))
+		})
+		It("stops at the first failure without recording it", func() {
+			failing := append(append([]mongodb.Migration(nil), mongodb.Migrations...), mongodb.Migration{
+				Version: 100,
+				Name:    "boom",
+				Up:      func(context.Context, *mongo.Database) error { return errors.New("boom") },
+				Down:    func(context.Context, *mongo.Database) error { return nil },
+			})
+			_, err := mongodb.NewMigrator(db, failing).Up(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("100 boom")))
+
+			statuses, err := target.Status(context.TODO())
+			Expect(err).To(BeNil())
+			for _, status := range statuses {
+				Expect(status.AppliedAt.IsZero()).To(BeFalse())
+			}
+		})
+		It("keeps the lock while a migration outlasts its TTL", func() {
+			slow := mongodb.NewMigrator(db, []mongodb.Migration{{
+				Version: 1,
+				Name:    "slow",
+				Up: func(ctx context.Context, db *mongo.Database) error {
+					time.Sleep(time.Second)
+					var lock struct {
+						ExpiresAt time.Time `bson:"expires_at"`
+					}
+					err := db.Collection("migration_locks").FindOne(ctx, bson.D{{Key: "_id", Value: "migrations"}}).Decode(&lock)
+					if err != nil {
+						return err
+					}
+					if !lock.ExpiresAt.After(time.Now()) {
+						return errors.New("the lock expired")
+					}
+					return nil
+				},
+				Down: func(context.Context, *mongo.Database) error { return nil },
+			}})
+			slow.SetLockTTL(300 * time.Millisecond)
+			_, err := slow.Up(context.TODO())
+			Expect(err).To(BeNil())
+		})
+		It("stops migrating once another runner takes the lock over", func() {
+			stolen := mongodb.NewMigrator(db, []mongodb.Migration{{
+				Version: 1,
+				Name:    "stolen",
+				Up: func(ctx context.Context, db *mongo.Database) error {
+					_, err := db.Collection("migration_locks").DeleteOne(ctx, bson.D{{Key: "_id", Value: "migrations"}})
+					if err != nil {
+						return err
+					}
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(5 * time.Second):
+						return nil
+					}
+				},
+				Down: func(context.Context, *mongo.Database) error { return nil },
+			}})
+			stolen.SetLockTTL(300 * time.Millisecond)
+			_, err := stolen.Up(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("lost the migration lock")))
+		})
+	})
+
+	Context("Down", func() {
+		BeforeEach(func() {
+			_, err := target.Up(context.TODO())
+			Expect(err).To(BeNil())
+		})
+		It("rolls back the latest migrations first", func() {
+			rolledBack, err := target.Down(context.TODO(), 2)
+			Expect(err).To(BeNil())
+			last := len(mongodb.Migrations)
+			Expect(versions(rolledBack)).To(Equal([]int{last, last - 1}))
+
+			statuses, err := target.Status(context.TODO())
+			Expect(err).To(BeNil())
+			Expect(statuses).To(HaveLen(last))
+			Expect(statuses[last-3].AppliedAt.IsZero()).To(BeFalse())
+			Expect(statuses[last-2].AppliedAt.IsZero()).To(BeTrue())
+			Expect(statuses[last-1].AppliedAt.IsZero()).To(BeTrue())
+		})
+		It("drops the email index", func() {
+			_, err := target.Down(context.TODO(), len(mongodb.Migrations))
+			Expect(err).To(BeNil())
+
+			users := db.Collection("users")
+			for i := 0; i < 2; i++ {
+				_, err = users.InsertOne(context.TODO(), bson.D{{Key: "email", Value: "test@test.com"}})
+				Expect(err).To(BeNil())
+			}
+		})
+		It("can be applied again afterwards", func() {
+			_, err := target.Down(context.TODO(), 1)
+			Expect(err).To(BeNil())
+			applied, err := target.Up(context.TODO())
+			Expect(err).To(BeNil())
+			Expect(versions(applied)).To(Equal([]int{len(mongodb.Migrations)}))
+		})
+		It("refuses to roll back migrations this build does not know", func() {
+			_, err := mongodb.NewMigrator(db, mongodb.Migrations[:1]).Down(context.TODO(), 1)
+			Expect(err).To(MatchError(ContainSubstring("unknown to this build")))
+		})
+	})
+})
@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations is the schema of the application's database, in the order it was introduced. Never edit or remove an
+// applied migration; add a new one instead.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_users_email_index",
+		Up: createIndex("users", mongo.IndexModel{
+			// email uniqueness is enforced by the database rather than the repository
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email_unique").SetUnique(true),
+		}),
+		Down: dropIndex("users", "email_unique"),
+	},
+	{
+		Version: 2,
+		Name:    "create_habits_user_id_index",
+		Up: createIndex("habits", mongo.IndexModel{
+			// covers listing a user's habits, which are paged in id order
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("user_id_id"),
+		}),
+		Down: dropIndex("habits", "user_id_id"),
+	},
+	{
+		Version: 3,
+		Name:    "create_rate_limits_expiry_index",
+		Up: createIndex("rate_limits", mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		}),
+		Down: dropIndex("rate_limits", "expires_at_ttl"),
+	},
+	{
+		Version: 4,
+		Name:    "backfill_versions",
+		// documents written before versions existed start at the first version
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			missing := bson.D{{Key: "version", Value: bson.D{{Key: "$exists", Value: false}}}}
+			for _, collection := range []string{"users", "habits"} {
+				update := bson.D{{Key: "$set", Value: bson.D{{Key: "version", Value: int64(1)}}}}
+				if _, err := db.Collection(collection).UpdateMany(ctx, missing, update); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, collection := range []string{"users", "habits"} {
+				update := bson.D{{Key: "$unset", Value: bson.D{{Key: "version", Value: ""}}}}
+				if _, err := db.Collection(collection).UpdateMany(ctx, bson.D{}, update); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_check_ins_habit_id_date_index",
+		Up: createIndex("check_ins", mongo.IndexModel{
+			// a habit is checked in at most once a day; the index also serves a habit's check-ins latest first
+			Keys:    bson.D{{Key: "habit_id", Value: 1}, {Key: "date", Value: 1}},
+			Options: options.Index().SetName("habit_id_date_unique").SetUnique(true),
+		}),
+		Down: dropIndex("check_ins", "habit_id_date_unique"),
+	},
+	{
+		Version: 6,
+		Name:    "create_check_ins_user_id_index",
+		Up: createIndex("check_ins", mongo.IndexModel{
+			// covers deleting a user's check-ins along with the user
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		}),
+		Down: dropIndex("check_ins", "user_id"),
+	},
+	{
+		Version: 7,
+		Name:    "create_habits_deleted_at_index",
+		Up: createIndex("habits", mongo.IndexModel{
+			// sparse, as only habits in the trash have deleted_at; covers purging them
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetName("deleted_at").SetSparse(true),
+		}),
+		Down: dropIndex("habits", "deleted_at"),
+	},
+	{
+		Version: 8,
+		Name:    "create_check_ins_deleted_at_index",
+		Up: createIndex("check_ins", mongo.IndexModel{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetName("deleted_at").SetSparse(true),
+		}),
+		Down: dropIndex("check_ins", "deleted_at"),
+	},
+	{
+		Version: 9,
+		Name:    "backfill_habit_totals",
+		// habits keep their best streak and check-in count so that stats read one document per habit; existing
+		// habits take them from their check-ins, including those in the trash along with their habit
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			cursor, err := db.Collection("check_ins").Aggregate(ctx, mongo.Pipeline{
+				{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$habit_id"},
+					{Key: "best_streak", Value: bson.D{{Key: "$max", Value: "$streak"}}},
+					{Key: "check_ins", Value: bson.D{{Key: "$sum", Value: int64(1)}}},
+				}}},
+				{{Key: "$merge", Value: bson.D{
+					{Key: "into", Value: "habits"},
+					{Key: "whenMatched", Value: "merge"},
+					{Key: "whenNotMatched", Value: "discard"},
+				}}},
+			})
+			if err != nil {
+				return err
+			}
+			return cursor.Close(ctx)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			update := bson.D{{Key: "$unset", Value: bson.D{{Key: "best_streak", Value: ""}, {Key: "check_ins", Value: ""}}}}
+			_, err := db.Collection("habits").UpdateMany(ctx, bson.D{}, update)
+			return err
+		},
+	},
+}
+
+// createIndex creates an index; creating an identical index again is a no-op, which makes it safe to retry.
+func createIndex(collection string, model mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateOne(ctx, model)
+		return err
+	}
+}
+
+// dropIndex drops an index, succeeding when the index or its collection is already gone.
+func dropIndex(collection, name string) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,13 @@
+package mongodb_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMongodb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mongodb Suite")
+}
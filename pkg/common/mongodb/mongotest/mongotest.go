@@ -0,0 +1,39 @@
+// Package mongotest gives Ginkgo specs a throwaway database on a real Mongo server.
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnvURI names the environment variable holding the connection string of the server specs run against.
+const EnvURI = "CADENCE_TEST_MONGO_URI"
+
+// Database connects to the server named by EnvURI and returns a new, uniquely named database that is dropped when
+// the spec ends. The spec is skipped when the variable is unset.
+func Database() *mongo.Database {
+	uri := os.Getenv(EnvURI)
+	if uri == "" {
+		Skip(EnvURI + " is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	Expect(err).To(BeNil())
+
+	db := client.Database(fmt.Sprintf("cadence_test_%s", primitive.NewObjectID().Hex()))
+	DeferCleanup(func(ctx SpecContext) {
+		Expect(db.Drop(ctx)).To(Succeed())
+		Expect(client.Disconnect(ctx)).To(Succeed())
+	})
+	return db
+}
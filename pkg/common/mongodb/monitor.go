@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CombineMonitors returns a monitor that forwards every command event to each of monitors in order, since the driver
+// accepts only one. Nil monitors and nil callbacks are skipped.
+func CombineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m != nil && m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
+
+// CollectionOf reads the collection from a CRUD command such as {"find": "users", ...}, falling back to the database
+// for commands that do not target a collection, like ping.
+func CollectionOf(e *event.CommandStartedEvent) string {
+	if value, err := e.Command.LookupErr(e.CommandName); err == nil {
+		if collection, ok := value.StringValueOK(); ok {
+			return collection
+		}
+	}
+	return e.DatabaseName
+}
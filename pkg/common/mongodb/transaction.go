@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// transactionAttempts bounds how many times a transaction that fails with a transient error is run.
+	transactionAttempts = 5
+	// commitAttempts bounds how many times a commit whose outcome is unknown is retried.
+	commitAttempts = 3
+	// transactionBackoff is multiplied by the attempt number to get the pause before running a transaction again.
+	transactionBackoff = 20 * time.Millisecond
+
+	transientTransactionError      = "TransientTransactionError"
+	unknownTransactionCommitResult = "UnknownTransactionCommitResult"
+)
+
+// TransactionRunner runs units of work in multi-document transactions on client's sessions. Repositories take part
+// by passing the session context they are given to the driver, which every repository in this module already does.
+type TransactionRunner struct {
+	client *mongo.Client
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// NewTransactionRunner creates a runner for client. Transactions need a replica set or a sharded cluster; against a
+// standalone server, which rejects them, units of work run without a transaction and a warning is logged once.
+func NewTransactionRunner(client *mongo.Client, logger *slog.Logger) *TransactionRunner {
+	return &TransactionRunner{client: client, logger: logger}
+}
+
+var _ transaction.Runner = (*TransactionRunner)(nil)
+
+// Run runs fn in a transaction, running it again with a fresh transaction when it fails with an error labelled
+// TransientTransactionError, such as a write conflict with a concurrent transaction, and retrying the commit when its
+// outcome is unknown.
+func (r *TransactionRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	supported, err := r.supportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fn(ctx)
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	for attempt := 1; ; attempt++ {
+		err = runTransaction(ctx, session, fn)
+		if err == nil || attempt == transactionAttempts || !hasErrorLabel(err, transientTransactionError) {
+			return err
+		}
+		r.logger.DebugContext(ctx, "retrying transaction", slog.Int("attempt", attempt), slog.Any("error", err))
+
+		timer := time.NewTimer(time.Duration(attempt) * transactionBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return TranslateError(ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runTransaction makes a single attempt at running fn in a transaction on session and committing it.
+func runTransaction(ctx context.Context, session mongo.Session, fn func(ctx context.Context) error) error {
+	if err := session.StartTransaction(); err != nil {
+		return TranslateError(err)
+	}
+	if err := fn(mongo.NewSessionContext(ctx, session)); err != nil {
+		// abort even when ctx is done, so the server does not hold the transaction's locks until it times out
+		_ = session.AbortTransaction(context.WithoutCancel(ctx))
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := session.CommitTransaction(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt == commitAttempts || !hasErrorLabel(err, unknownTransactionCommitResult) {
+			return TranslateError(err)
+		}
+	}
+}
+
+// hasErrorLabel reports whether the server attached label to err or to any error it wraps.
+func hasErrorLabel(err error, label string) bool {
+	var labelled interface{ HasErrorLabel(string) bool }
+	return errors.As(err, &labelled) && labelled.HasErrorLabel(label)
+}
+
+// supportsTransactions asks the server once whether it is a replica set member or a mongos router.
+func (r *TransactionRunner) supportsTransactions(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.checked {
+		return r.supported, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := r.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, TranslateError(err)
+	}
+	r.checked = true
+	r.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !r.supported {
+		r.logger.WarnContext(ctx, "mongo deployment does not support transactions, units of work will not be atomic")
+	}
+	return r.supported, nil
+}
@@ -0,0 +1,121 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb/mongotest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ = Describe("TransactionRunner", func() {
+	var (
+		db         *mongo.Database
+		target     *mongodb.TransactionRunner
+		replicaSet bool
+		insert     func(ctx context.Context, name string) error
+		count      func() int64
+	)
+
+	BeforeEach(func() {
+		db = mongotest.Database()
+		target = mongodb.NewTransactionRunner(db.Client(), logging.Discard())
+
+		var hello struct {
+			SetName string `bson:"setName"`
+		}
+		Expect(db.Client().Database("admin").RunCommand(context.TODO(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello)).To(Succeed())
+		replicaSet = hello.SetName != ""
+
+		insert = func(ctx context.Context, name string) error {
+			_, err := db.Collection("things").InsertOne(ctx, bson.D{{Key: "name", Value: name}})
+			return err
+		}
+		count = func() int64 {
+			n, err := db.Collection("things").CountDocuments(context.TODO(), bson.D{})
+			Expect(err).To(BeNil())
+			return n
+		}
+	})
+
+	Context("on a replica set", func() {
+		BeforeEach(func() {
+			if !replicaSet {
+				Skip("transactions need a replica set")
+			}
+			// collections cannot always be created inside a transaction
+			Expect(db.CreateCollection(context.TODO(), "things")).To(Succeed())
+		})
+
+		It("commits the writes made with the session context", func() {
+			err := target.Run(context.TODO(), func(ctx context.Context) error {
+				Expect(mongo.SessionFromContext(ctx)).NotTo(BeNil())
+				Expect(insert(ctx, "a")).To(Succeed())
+				return insert(ctx, "b")
+			})
+			Expect(err).To(BeNil())
+			Expect(count()).To(Equal(int64(2)))
+		})
+
+		It("rolls every write back when fn fails", func() {
+			boom := errors.New("boom")
+			err := target.Run(context.TODO(), func(ctx context.Context) error {
+				Expect(insert(ctx, "a")).To(Succeed())
+				return boom
+			})
+			Expect(err).To(MatchError(boom))
+			Expect(count()).To(BeZero())
+		})
+
+		It("runs fn again after a transient transaction error", func() {
+			attempts := 0
+			err := target.Run(context.TODO(), func(ctx context.Context) error {
+				attempts++
+				if err := insert(ctx, "a"); err != nil {
+					return err
+				}
+				if attempts == 1 {
+					return mongo.CommandError{Name: "WriteConflict", Labels: []string{"TransientTransactionError"}}
+				}
+				return nil
+			})
+			Expect(err).To(BeNil())
+			Expect(attempts).To(Equal(2))
+			Expect(count()).To(Equal(int64(1)))
+		})
+
+		It("joins the surrounding transaction when nested", func() {
+			err := target.Run(context.TODO(), func(ctx context.Context) error {
+				Expect(target.Run(ctx, func(ctx context.Context) error { return insert(ctx, "a") })).To(Succeed())
+				return errors.New("boom")
+			})
+			Expect(err).NotTo(BeNil())
+			Expect(count()).To(BeZero())
+		})
+	})
+
+	Context("on a standalone server", func() {
+		BeforeEach(func() {
+			if replicaSet {
+				Skip("the server supports transactions")
+			}
+		})
+
+		It("runs fn once without a session", func() {
+			attempts := 0
+			err := target.Run(context.TODO(), func(ctx context.Context) error {
+				attempts++
+				Expect(mongo.SessionFromContext(ctx)).To(BeNil())
+				return insert(ctx, "a")
+			})
+			Expect(err).To(BeNil())
+			Expect(attempts).To(Equal(1))
+			Expect(count()).To(Equal(int64(1)))
+		})
+	})
+})
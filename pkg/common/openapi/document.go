@@ -0,0 +1,67 @@
+package openapi
+
+// The types below are the subset of the OpenAPI 3.0 object model that the generator emits.
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps a lower case HTTP method to its operation.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationId string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []ParameterObject          `json:"parameters,omitempty"`
+	RequestBody *RequestBodyObject         `json:"requestBody,omitempty"`
+	Responses   map[string]*ResponseObject `json:"responses"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBodyObject struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
@@ -0,0 +1,323 @@
+// Package openapi generates an OpenAPI 3 document from the routes registered on a gin engine and the operations
+// controllers describe for them.
+package openapi
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/problem"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	specPath   = "/openapi.json"
+	docsPath   = "/docs"
+	assetsPath = docsPath + "/assets"
+
+	// Swagger UI is served from assetsPath and its icons are inlined as data URIs
+	docsContentSecurityPolicy = "default-src 'self'; img-src 'self' data:; frame-ancestors 'none'"
+)
+
+//go:embed swagger.html
+var swaggerPage []byte
+
+// swaggerAssets is the Swagger UI 5.2.0 distribution (Apache 2.0, see swagger-ui/LICENSE), served by the API itself
+// so the docs page does not depend on a CDN.
+//
+//go:embed swagger-ui
+var swaggerAssets embed.FS
+
+// Operation describes a single route. Path uses gin syntax (":param") and must match the registered route exactly.
+// Request and response bodies are given as zero values of the Go type that is (un)marshalled, e.g. domain.User{}.
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Tags        []string
+	Request     interface{}
+	Responses   map[int]Response
+	QueryParams []Parameter
+}
+
+type Response struct {
+	Description string
+	Body        interface{}
+	// LegacyBody documents the body served instead of Body on Legacy groups.
+	LegacyBody interface{}
+}
+
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+	// Type is a zero value of the parameter's Go type; strings are assumed when nil.
+	Type interface{}
+}
+
+// Documenter is implemented by controllers to describe the routes they register.
+type Documenter interface {
+	Operations() []Operation
+}
+
+// Group describes where a set of documenters is mounted. Operation paths are relative to Prefix.
+type Group struct {
+	Prefix     string
+	Deprecated bool
+	// Legacy groups answer with the response.Legacy bodies of the unversioned routes.
+	Legacy bool
+}
+
+type groupDocumenter struct {
+	group Group
+	Documenter
+}
+
+type Generator struct {
+	info        Info
+	documenters []groupDocumenter
+
+	once sync.Once
+	doc  Document
+	err  error
+}
+
+func NewGenerator(info Info) *Generator {
+	return &Generator{info: info}
+}
+
+// Add registers documenters mounted directly on the engine.
+func (g *Generator) Add(docs ...Documenter) {
+	g.AddGroup(Group{}, docs...)
+}
+
+// AddGroup registers documenters mounted on a route group.
+func (g *Generator) AddGroup(group Group, docs ...Documenter) {
+	for _, d := range docs {
+		g.documenters = append(g.documenters, groupDocumenter{group: group, Documenter: d})
+	}
+}
+
+// Generate builds the document for the given routes. It fails if any route is undocumented or any operation does
+// not correspond to a registered route, so the spec can never silently drift from the router.
+func (g *Generator) Generate(routes gin.RoutesInfo) (Document, error) {
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[routeKey(route.Method, route.Path)] = true
+	}
+
+	registry := newSchemaRegistry()
+	doc := Document{
+		OpenAPI:    "3.0.3",
+		Info:       g.info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: registry.schemas},
+	}
+
+	var drift []string
+	documented := map[string]bool{}
+	for _, d := range g.documenters {
+		for _, op := range d.Operations() {
+			op.Path = joinPath(d.group.Prefix, op.Path)
+			key := routeKey(op.Method, op.Path)
+			if documented[key] {
+				drift = append(drift, "duplicate operation "+key)
+				continue
+			}
+			documented[key] = true
+			if !registered[key] {
+				drift = append(drift, "operation without route "+key)
+				continue
+			}
+
+			path := specPathFor(op.Path)
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = PathItem{}
+			}
+			obj := registry.operation(op, d.group.Legacy)
+			obj.Deprecated = d.group.Deprecated
+			doc.Paths[path][strings.ToLower(op.Method)] = obj
+		}
+	}
+	for _, route := range routes {
+		if key := routeKey(route.Method, route.Path); !documented[key] {
+			drift = append(drift, "route without operation "+key)
+		}
+	}
+
+	if len(drift) > 0 {
+		sort.Strings(drift)
+		return Document{}, fmt.Errorf("openapi spec does not match routes: %s", strings.Join(drift, "; "))
+	}
+	return doc, nil
+}
+
+// RegisterRoutes serves the generated document and a Swagger UI page for it. The document is generated from the
+// engine's routes on first request, so this should be called after every controller has registered its routes.
+func (g *Generator) RegisterRoutes(engine *gin.Engine) {
+	g.Add(g)
+	engine.GET(specPath, func(ctx *gin.Context) {
+		g.once.Do(func() {
+			g.doc, g.err = g.Generate(engine.Routes())
+		})
+		if g.err != nil {
+			_ = ctx.Error(g.err)
+			return
+		}
+		ctx.JSON(http.StatusOK, g.doc)
+	})
+	engine.GET(docsPath, func(ctx *gin.Context) {
+		ctx.Header("Content-Security-Policy", docsContentSecurityPolicy)
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerPage)
+	})
+
+	assets, _ := fs.Sub(swaggerAssets, "swagger-ui")
+	engine.GET(assetsPath+"/*asset", func(ctx *gin.Context) {
+		name := strings.TrimPrefix(ctx.Param("asset"), "/")
+		if info, err := fs.Stat(assets, name); err != nil || info.IsDir() {
+			_ = ctx.Error(cadence_errors.New(cadence_errors.NotFound, "", "not found"))
+			return
+		}
+		ctx.FileFromFS(name, http.FS(assets))
+	})
+}
+
+func (g *Generator) Operations() []Operation {
+	return []Operation{
+		{
+			Method:    http.MethodGet,
+			Path:      specPath,
+			Summary:   "OpenAPI document for this API",
+			Tags:      []string{"docs"},
+			Responses: map[int]Response{http.StatusOK: {Description: "OpenAPI 3 document", Body: Document{}}},
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      docsPath,
+			Summary:   "Swagger UI",
+			Tags:      []string{"docs"},
+			Responses: map[int]Response{http.StatusOK: {Description: "HTML page"}},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    assetsPath + "/*asset",
+			Summary: "Swagger UI assets",
+			Tags:    []string{"docs"},
+			Responses: map[int]Response{
+				http.StatusOK:       {Description: "Static file"},
+				http.StatusNotFound: {Description: "No such asset"},
+			},
+		},
+	}
+}
+
+func (r *schemaRegistry) operation(op Operation, legacy bool) *OperationObject {
+	o := &OperationObject{
+		OperationId: operationId(op.Method, op.Path),
+		Summary:     op.Summary,
+		Tags:        op.Tags,
+		Responses:   map[string]*ResponseObject{},
+	}
+
+	for _, segment := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			o.Parameters = append(o.Parameters, ParameterObject{
+				Name:     segment[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	for _, p := range op.QueryParams {
+		schema := &Schema{Type: "string"}
+		if p.Type != nil {
+			schema = r.schemaFor(reflect.TypeOf(p.Type))
+		}
+		o.Parameters = append(o.Parameters, ParameterObject{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      schema,
+		})
+	}
+
+	if op.Request != nil {
+		o.RequestBody = &RequestBodyObject{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: r.schemaFor(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	var errorBody interface{} = response.Envelope[any]{}
+	if legacy {
+		errorBody = response.Legacy[string]{}
+	}
+	for status, resp := range op.Responses {
+		obj := &ResponseObject{Description: resp.Description}
+		body := resp.Body
+		if legacy && resp.LegacyBody != nil {
+			body = resp.LegacyBody
+		}
+		if body != nil {
+			obj.Content = map[string]MediaType{
+				"application/json": {Schema: r.schemaFor(reflect.TypeOf(body))},
+			}
+		}
+		o.Responses[strconv.Itoa(status)] = obj
+	}
+	o.Responses["default"] = &ResponseObject{
+		Description: "Error. Clients that accept application/problem+json receive RFC 7807 problem details.",
+		Content: map[string]MediaType{
+			"application/json":  {Schema: r.schemaFor(reflect.TypeOf(errorBody))},
+			problem.ContentType: {Schema: r.schemaFor(reflect.TypeOf(problem.Details{}))},
+		},
+	}
+	return o
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return "/" + strings.Trim(prefix, "/") + path
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// specPathFor converts gin path parameters (":id", "*path") to OpenAPI templates ("{id}").
+func specPathFor(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationId(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		for _, part := range strings.FieldsFunc(segment, func(r rune) bool { return r == '.' || r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type widget struct {
+	Id    primitive.ObjectID `json:"id"`
+	Name  string             `json:"name" validate:"required"`
+	Email string             `json:"email,omitempty" validate:"email"`
+	Tags  []string           `json:"tags"`
+}
+
+type documenter []openapi.Operation
+
+func (d documenter) Operations() []openapi.Operation {
+	return d
+}
+
+var _ = Describe("Generator", func() {
+	var (
+		router *gin.Engine
+		ops    documenter
+		target *openapi.Generator
+		doc    openapi.Document
+		err    error
+	)
+
+	BeforeEach(func() {
+		router = gin.New()
+		router.GET("/widget/:widgetId", func(ctx *gin.Context) {})
+		ops = documenter{{
+			Method: http.MethodGet,
+			Path:   "/widget/:widgetId",
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "ok", Body: response.Envelope[widget]{}},
+			},
+		}}
+		target = openapi.NewGenerator(openapi.Info{Title: "test", Version: "1"})
+	})
+
+	JustBeforeEach(func() {
+		target.Add(ops)
+		doc, err = target.Generate(router.Routes())
+	})
+
+	Context("every route is documented", func() {
+		It("converts the path and describes the response schema", func() {
+			Expect(err).To(BeNil())
+			Expect(doc.Paths).To(HaveKey("/widget/{widgetId}"))
+
+			op := doc.Paths["/widget/{widgetId}"]["get"]
+			Expect(op.Parameters).To(ConsistOf(HaveField("Name", "widgetId")))
+			Expect(op.Responses["200"].Content["application/json"].Schema.Ref).
+				To(Equal("#/components/schemas/EnvelopeWidget"))
+
+			schema := doc.Components.Schemas["widget"]
+			Expect(schema.Required).To(ConsistOf("name"))
+			Expect(schema.Properties["id"].Pattern).NotTo(BeEmpty())
+			Expect(schema.Properties["email"].Format).To(Equal("email"))
+			Expect(schema.Properties["tags"].Type).To(Equal("array"))
+		})
+	})
+
+	Context("a route is not documented", func() {
+		BeforeEach(func() {
+			router.DELETE("/widget/:widgetId", func(ctx *gin.Context) {})
+		})
+		It("returns a drift error", func() {
+			Expect(err).To(MatchError(ContainSubstring("route without operation DELETE /widget/:widgetId")))
+		})
+	})
+
+	Context("an operation has no route", func() {
+		BeforeEach(func() {
+			ops = append(ops, openapi.Operation{Method: http.MethodPost, Path: "/widget"})
+		})
+		It("returns a drift error", func() {
+			Expect(err).To(MatchError(ContainSubstring("operation without route POST /widget")))
+		})
+	})
+
+	Context("serving the document", func() {
+		It("serves the spec and the swagger page", func() {
+			target.RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/openapi.json", nil)
+			router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(200))
+
+			var served map[string]interface{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &served)).To(Succeed())
+			Expect(served).To(HaveKeyWithValue("openapi", "3.0.3"))
+
+			w = httptest.NewRecorder()
+			request, _ = http.NewRequest("GET", "/docs", nil)
+			router.ServeHTTP(w, request)
+			Expect(w.Code).To(Equal(200))
+			Expect(w.Body.String()).To(ContainSubstring("swagger-ui"))
+			Expect(w.Body.String()).NotTo(ContainSubstring("https://"))
+			Expect(w.Header().Get("Content-Security-Policy")).NotTo(ContainSubstring("unsafe-inline"))
+			Expect(w.Header().Get("Content-Security-Policy")).NotTo(ContainSubstring("https:"))
+		})
+
+		It("serves the Swagger UI assets the page loads", func() {
+			target.RegisterRoutes(router)
+
+			for _, asset := range []string{"swagger-ui-bundle.js", "swagger-ui.css", "init.js"} {
+				w := httptest.NewRecorder()
+				request, _ := http.NewRequest("GET", "/docs/assets/"+asset, nil)
+				router.ServeHTTP(w, request)
+				Expect(w.Code).To(Equal(200), asset)
+				Expect(w.Body.Len()).To(BeNumerically(">", 0), asset)
+			}
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	objectIdType = reflect.TypeOf(primitive.ObjectID{})
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// schemaRegistry converts Go types into schemas, collecting every named struct into components so that it is only
+// described once.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]*Schema{}}
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case objectIdType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := r.schemaFor(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return r.structRef(t)
+	default:
+		// interface{} and anything we cannot describe accepts any value
+		return &Schema{}
+	}
+}
+
+func (r *schemaRegistry) structRef(t reflect.Type) *Schema {
+	name := schemaName(t)
+	if name == "" {
+		return r.structSchema(t)
+	}
+	if _, ok := r.schemas[name]; !ok {
+		// reserve the name first so recursive types terminate
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitted := jsonName(field)
+		if omitted {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := r.structSchema(field.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaFor(field.Type)
+		applyValidateTag(prop, field.Tag.Get("validate"))
+		s.Properties[name] = prop
+		if hasRule(field.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+func applyValidateTag(s *Schema, tag string) {
+	if s.Ref == "" && hasRule(tag, "email") {
+		s.Format = "email"
+	}
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaName returns a component name for a named type. Generic instantiations such as
+// response.Envelope[[]domain.Habit] become EnvelopeHabitList.
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	base, args, generic := strings.Cut(name, "[")
+	if !generic {
+		return name
+	}
+
+	var b strings.Builder
+	b.WriteString(base)
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		arg = strings.TrimSpace(arg)
+		list := strings.HasPrefix(arg, "[]")
+		arg = strings.TrimPrefix(arg, "[]")
+		if i := strings.LastIndex(arg, "."); i >= 0 {
+			arg = arg[i+1:]
+		}
+		if arg == "interface {}" || arg == "any" {
+			arg = "Any"
+		}
+		b.WriteString(strings.ToUpper(arg[:1]) + arg[1:])
+		if list {
+			b.WriteString("List")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package pagination
+
+import (
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Page is an offset based page request passed from controllers down to repositories.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// New validates a page request. A zero limit selects DefaultLimit.
+func New(limit, offset int) (Page, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 0 || limit > MaxLimit {
+		return Page{}, cadence_errors.New(cadence_errors.Validation, "pagination.invalid_limit", "limit must be between 1 and 100").
+			WithField("limit", "must be between 1 and 100")
+	}
+	if offset < 0 {
+		return Page{}, cadence_errors.New(cadence_errors.Validation, "pagination.invalid_offset", "offset must not be negative").
+			WithField("offset", "must not be negative")
+	}
+	return Page{Limit: limit, Offset: offset}, nil
+}
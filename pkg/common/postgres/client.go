@@ -0,0 +1,44 @@
+// Package postgres connects to PostgreSQL and keeps its schema up to date.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewPool creates a pool for dsn without connecting it, so repositories can be built before the application starts.
+func NewPool(dsn string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse postgres dsn: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres pool: %w", err)
+	}
+	return pool, nil
+}
+
+// Hook pings the server and applies pending migrations when the lifecycle starts, giving up on the connection after
+// connectTimeout, and closes the pool when it stops.
+func Hook(pool *pgxpool.Pool, connectTimeout time.Duration) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "postgres",
+		Start: func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+			defer cancel()
+			if err := pool.Ping(pingCtx); err != nil {
+				return fmt.Errorf("failed to connect to postgres: %w", err)
+			}
+			return Migrate(ctx, pool)
+		},
+		Stop: func(context.Context) error {
+			pool.Close()
+			return nil
+		},
+	}
+}
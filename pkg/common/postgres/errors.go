@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+var translator = storage.Translator{Engine: "postgres", Container: "table", Classify: classify}
+
+func classify(err error) storage.Failure {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return storage.Missing
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return storage.Duplicate
+	case pgconn.Timeout(err):
+		return storage.TimedOut
+	default:
+		return storage.Failed
+	}
+}
+
+// TranslateError converts a pgx error into a cadence_errors.Error. A nil error is returned unchanged.
+func TranslateError(err error) error {
+	return translator.TranslateError(err)
+}
+
+// TranslateAndLog translates err like TranslateError and logs the failures that are hidden from clients.
+func TranslateAndLog(ctx context.Context, logger *slog.Logger, table, operation string, err error) error {
+	return translator.TranslateAndLog(ctx, logger, table, operation, err)
+}
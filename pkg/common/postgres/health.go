@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/health"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HealthChecker pings the server.
+func HealthChecker(pool *pgxpool.Pool) health.Checker {
+	return health.CheckerFunc("postgres", func(ctx context.Context) error {
+		return pool.Ping(ctx)
+	})
+}
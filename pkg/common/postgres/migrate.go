@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlmigrate"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLock is the advisory lock key held while migrating, so that instances starting together apply each
+// migration once.
+const migrationLock = 7_436_210_001
+
+// Migrate applies every embedded migration that has not been applied yet, each in its own transaction, recording
+// them in schema_migrations.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	pending, err := sqlmigrate.Load(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to load postgres migrations: %w", err)
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migrations: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLock)
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied := map[int]bool{}
+	rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	for _, m := range pending {
+		if applied[m.Version] {
+			continue
+		}
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, m.SQL); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
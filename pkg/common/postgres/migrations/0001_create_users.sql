@@ -0,0 +1,4 @@
+CREATE TABLE users (
+    id    CHAR(24) PRIMARY KEY,
+    email TEXT     NOT NULL UNIQUE
+);
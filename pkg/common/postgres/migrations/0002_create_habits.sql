@@ -0,0 +1,11 @@
+-- owners are checked by the habit service rather than a foreign key, matching the mongo collections
+CREATE TABLE habits (
+    id             CHAR(24) PRIMARY KEY,
+    user_id        CHAR(24) NOT NULL,
+    name           TEXT     NOT NULL,
+    cadence        SMALLINT NOT NULL,
+    repeating_days INTEGER[],
+    streak         BIGINT   NOT NULL DEFAULT 0
+);
+
+CREATE INDEX habits_user_id_id ON habits (user_id, id);
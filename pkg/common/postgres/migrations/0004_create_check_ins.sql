@@ -0,0 +1,11 @@
+-- like habits, check-ins reference their habit and owner without foreign keys
+CREATE TABLE check_ins (
+    id       CHAR(24) PRIMARY KEY,
+    habit_id CHAR(24) NOT NULL,
+    user_id  CHAR(24) NOT NULL,
+    date     DATE     NOT NULL,
+    streak   BIGINT   NOT NULL,
+    UNIQUE (habit_id, date)
+);
+
+CREATE INDEX check_ins_user_id ON check_ins (user_id);
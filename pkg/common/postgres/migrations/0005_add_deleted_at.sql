@@ -0,0 +1,6 @@
+-- habits and their check-ins stay in the trash, restorable, until deleted_at is past the retention
+ALTER TABLE habits ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE check_ins ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX habits_deleted_at ON habits (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX check_ins_deleted_at ON check_ins (deleted_at) WHERE deleted_at IS NOT NULL;
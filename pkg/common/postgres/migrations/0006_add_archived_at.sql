@@ -0,0 +1,2 @@
+-- archived habits keep their check-ins but are left out of listings unless asked for
+ALTER TABLE habits ADD COLUMN archived_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+-- habits keep their best streak and check-in count so that stats read one row per habit; existing habits take them
+-- from their check-ins, including those in the trash along with their habit
+ALTER TABLE habits ADD COLUMN best_streak BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE habits ADD COLUMN check_ins BIGINT NOT NULL DEFAULT 0;
+
+UPDATE habits SET
+    best_streak = totals.best_streak,
+    check_ins = totals.check_ins
+FROM (SELECT habit_id, max(streak) AS best_streak, count(*) AS check_ins FROM check_ins GROUP BY habit_id) AS totals
+WHERE habits.id = totals.habit_id;
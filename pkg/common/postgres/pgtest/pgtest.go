@@ -0,0 +1,103 @@
+// Package pgtest gives Ginkgo specs a throwaway, migrated database on a real PostgreSQL server.
+package pgtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// EnvDSN names the environment variable holding the DSN of an existing server to run specs against.
+	EnvDSN = "CADENCE_TEST_POSTGRES_DSN"
+	// EnvBin names a directory holding initdb and pg_ctl, for when they are not on PATH.
+	EnvBin = "CADENCE_TEST_POSTGRES_BIN"
+)
+
+// Server returns the DSN of a server that specs can create databases on. It uses EnvDSN when set and otherwise starts
+// a cluster in a temporary directory from the local initdb and pg_ctl binaries, stopping it after the suite. Call it
+// from BeforeSuite; it returns "" when neither is available.
+func Server() string {
+	if dsn := os.Getenv(EnvDSN); dsn != "" {
+		return dsn
+	}
+
+	initdb, pgCtl := binary("initdb"), binary("pg_ctl")
+	if initdb == "" || pgCtl == "" {
+		return ""
+	}
+	// initdb refuses to run as root
+	if current, err := user.Current(); err != nil || current.Uid == "0" {
+		return ""
+	}
+
+	// the socket path is limited to ~100 bytes, which Ginkgo's temp dirs can exceed
+	dir, err := os.MkdirTemp("", "pgtest")
+	Expect(err).To(BeNil())
+	DeferCleanup(os.RemoveAll, dir)
+	data := filepath.Join(dir, "data")
+
+	run(initdb, "--pgdata", data, "--username", "postgres", "--auth", "trust", "--no-sync")
+	run(pgCtl, "start", "--wait", "--pgdata", data, "--log", filepath.Join(dir, "postgres.log"),
+		"-o", fmt.Sprintf("-c listen_addresses='' -k %s -F", dir))
+	DeferCleanup(run, pgCtl, "stop", "--pgdata", data, "--mode", "immediate")
+
+	return fmt.Sprintf("host=%s user=postgres dbname=postgres sslmode=disable", dir)
+}
+
+func binary(name string) string {
+	if dir := os.Getenv(EnvBin); dir != "" {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	path, _ := exec.LookPath(name)
+	return path
+}
+
+func run(name string, args ...string) {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	Expect(err).To(BeNil(), "%s failed: %s", name, output)
+}
+
+// Database creates a uniquely named database on the server at dsn, applies the migrations and returns a pool for it.
+// The database is dropped when the spec ends. The spec is skipped when dsn is "".
+func Database(dsn string) *pgxpool.Pool {
+	if dsn == "" {
+		Skip(fmt.Sprintf("neither %s nor a local postgres installation is available", EnvDSN))
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	Expect(err).To(BeNil())
+	DeferCleanup(admin.Close)
+
+	name := "cadence_test_" + primitive.NewObjectID().Hex()
+	_, err = admin.Exec(ctx, "CREATE DATABASE "+name)
+	Expect(err).To(BeNil())
+	DeferCleanup(func(ctx SpecContext) {
+		_, err := admin.Exec(ctx, "DROP DATABASE "+name+" WITH (FORCE)")
+		Expect(err).To(BeNil())
+	})
+
+	config, err := pgxpool.ParseConfig(dsn)
+	Expect(err).To(BeNil())
+	config.ConnConfig.Database = name
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	Expect(err).To(BeNil())
+	DeferCleanup(pool.Close)
+
+	Expect(postgres.Migrate(ctx, pool)).To(Succeed())
+	return pool
+}
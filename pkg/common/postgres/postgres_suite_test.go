@@ -0,0 +1,21 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres/pgtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// dsn is the server migrations run against, empty when none is available.
+var dsn string
+
+var _ = BeforeSuite(func() {
+	dsn = pgtest.Server()
+})
+
+func TestPostgres(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Postgres Suite")
+}
@@ -0,0 +1,50 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres/pgtest"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TranslateError", func() {
+	DescribeTable("maps driver errors to kinds",
+		func(err error, kind cadence_errors.Kind, code string) {
+			translated, ok := cadence_errors.As(postgres.TranslateError(err))
+			Expect(ok).To(BeTrue())
+			Expect(translated.Kind).To(Equal(kind))
+			Expect(translated.Code).To(Equal(code))
+			Expect(errors.Is(translated, err)).To(BeTrue())
+		},
+		Entry("no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), cadence_errors.NotFound, ""),
+		Entry("unique violation", &pgconn.PgError{Code: "23505"}, cadence_errors.Conflict, ""),
+		Entry("cancelled", context.Canceled, cadence_errors.Internal, "storage.timeout"),
+		Entry("anything else", &pgconn.PgError{Code: "42P01"}, cadence_errors.Internal, "storage.error"),
+	)
+
+	It("leaves nil alone", func() {
+		Expect(postgres.TranslateError(nil)).To(BeNil())
+	})
+})
+
+var _ = Describe("Migrate", func() {
+	It("is safe to run again and records each migration once", func() {
+		pool := pgtest.Database(dsn)
+		count := func() (applied int) {
+			Expect(pool.QueryRow(context.TODO(), "SELECT count(*) FROM schema_migrations").Scan(&applied)).To(Succeed())
+			return applied
+		}
+		applied := count()
+		Expect(applied).To(BeNumerically(">", 0))
+
+		Expect(postgres.Migrate(context.TODO(), pool)).To(Succeed())
+		Expect(count()).To(Equal(applied))
+	})
+})
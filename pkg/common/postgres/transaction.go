@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+
+	// transactionAttempts bounds how many times a transaction that was rolled back by the server is run.
+	transactionAttempts = 5
+)
+
+// Queryer is implemented by both *pgxpool.Pool and pgx.Tx, letting repositories run the same statements inside and
+// outside a transaction.
+type Queryer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction a TransactionRunner stored in ctx, or pool when ctx is not part of a transaction.
+func Conn(ctx context.Context, pool *pgxpool.Pool) Queryer {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// TransactionRunner runs units of work in transactions on pool. Repositories take part by running their statements
+// on Conn.
+type TransactionRunner struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransactionRunner(pool *pgxpool.Pool) *TransactionRunner {
+	return &TransactionRunner{pool: pool}
+}
+
+var _ transaction.Runner = (*TransactionRunner)(nil)
+
+// Run runs fn in a transaction, running it again when the server aborts the transaction with a serialization
+// failure or to break a deadlock.
+func (r *TransactionRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		err := r.run(ctx, fn)
+		if err == nil || attempt == transactionAttempts || !retryable(err) {
+			return err
+		}
+	}
+}
+
+func (r *TransactionRunner) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		// roll back even when ctx is done, so the connection goes back to the pool clean
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return TranslateError(err)
+	}
+	return nil
+}
+
+// retryable reports whether err, or an error it wraps, means the transaction can be run again as is.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected)
+}
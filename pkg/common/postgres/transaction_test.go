@@ -0,0 +1,80 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres/pgtest"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TransactionRunner", func() {
+	var (
+		pool   *pgxpool.Pool
+		target *postgres.TransactionRunner
+		insert func(ctx context.Context, id string) error
+		count  func() int
+	)
+
+	BeforeEach(func() {
+		pool = pgtest.Database(dsn)
+		target = postgres.NewTransactionRunner(pool)
+		insert = func(ctx context.Context, id string) error {
+			_, err := postgres.Conn(ctx, pool).Exec(ctx, "INSERT INTO users (id, email) VALUES ($1, $2)", id, id+"@test.com")
+			return err
+		}
+		count = func() (users int) {
+			Expect(pool.QueryRow(context.TODO(), "SELECT count(*) FROM users").Scan(&users)).To(Succeed())
+			return users
+		}
+	})
+
+	It("commits the writes made through Conn", func() {
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(insert(ctx, "aaaaaaaaaaaaaaaaaaaaaaaa")).To(Succeed())
+			return insert(ctx, "bbbbbbbbbbbbbbbbbbbbbbbb")
+		})
+		Expect(err).To(BeNil())
+		Expect(count()).To(Equal(2))
+	})
+
+	It("rolls every write back when fn fails", func() {
+		boom := errors.New("boom")
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(insert(ctx, "aaaaaaaaaaaaaaaaaaaaaaaa")).To(Succeed())
+			return boom
+		})
+		Expect(err).To(MatchError(boom))
+		Expect(count()).To(BeZero())
+	})
+
+	It("runs fn again after a serialization failure", func() {
+		attempts := 0
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			attempts++
+			if err := insert(ctx, "aaaaaaaaaaaaaaaaaaaaaaaa"); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+		Expect(err).To(BeNil())
+		Expect(attempts).To(Equal(2))
+		Expect(count()).To(Equal(1))
+	})
+
+	It("joins the surrounding transaction when nested", func() {
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(target.Run(ctx, func(ctx context.Context) error { return insert(ctx, "aaaaaaaaaaaaaaaaaaaaaaaa") })).To(Succeed())
+			return errors.New("boom")
+		})
+		Expect(err).NotTo(BeNil())
+		Expect(count()).To(BeZero())
+	})
+})
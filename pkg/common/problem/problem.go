@@ -0,0 +1,68 @@
+// Package problem renders errors as RFC 7807 "Problem Details for HTTP APIs" documents.
+package problem
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix namespaces problem types. Types are URNs rather than URLs since we do not host per-problem docs.
+const typePrefix = "urn:cadence:problem:"
+
+// Details is an RFC 7807 problem document. Code, Details, InvalidParams and RequestId are extension members.
+type Details struct {
+	Type          string                      `json:"type"`
+	Title         string                      `json:"title"`
+	Status        int                         `json:"status"`
+	Detail        string                      `json:"detail,omitempty"`
+	Instance      string                      `json:"instance,omitempty"`
+	Code          string                      `json:"code,omitempty"`
+	Details       map[string]interface{}      `json:"details,omitempty"`
+	InvalidParams []cadence_errors.FieldError `json:"invalid_params,omitempty"`
+	RequestId     string                      `json:"request_id,omitempty"`
+}
+
+// FromError builds a problem document for err. Only the client facing message of a cadence_errors.Error is used as
+// the detail; wrapped causes and untyped errors are never exposed.
+func FromError(err error, status int, instance string) Details {
+	d := Details{
+		Type:     typePrefix + cadence_errors.Internal.String(),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   "an unexpected error occurred",
+		Instance: instance,
+	}
+
+	e, ok := cadence_errors.As(err)
+	if !ok || e.Kind == cadence_errors.Internal {
+		return d
+	}
+
+	d.Type = typePrefix + e.Kind.String()
+	d.Detail = e.Message
+	d.Code = e.Code
+	d.Details = e.Details
+	d.InvalidParams = e.Fields
+	return d
+}
+
+// Accepted reports whether the Accept header explicitly lists the problem+json media type. Wildcards do not count so
+// that existing clients keep receiving the legacy envelope.
+func Accepted(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != ContentType {
+			continue
+		}
+		if q, ok := params["q"]; ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryBackend keeps buckets in process. Limits are per instance, so it suits single instance deployments and
+// tests.
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{buckets: map[string]*bucket{}}
+}
+
+func (b *MemoryBackend) Take(_ context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+
+	capacity := float64(policy.Limit)
+	current, ok := b.buckets[key]
+	if !ok {
+		current = &bucket{tokens: capacity, updated: now}
+		b.buckets[key] = current
+	}
+	current.period = policy.Period
+	elapsed := now.Sub(current.updated).Seconds()
+	if elapsed > 0 {
+		current.tokens = math.Min(capacity, current.tokens+elapsed*policy.ratePerSecond())
+		current.updated = now
+	}
+
+	allowed := current.tokens >= 1
+	if allowed {
+		current.tokens--
+	}
+	return newResult(policy, current.tokens, allowed), nil
+}
+
+// sweep drops buckets that have had time to refill completely, as they are indistinguishable from new ones. It runs
+// at most once a minute to keep Take cheap.
+func (b *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+	b.lastSweep = now
+	for key, current := range b.buckets {
+		if now.Sub(current.updated) >= current.period {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the caller's API key.
+const APIKeyHeader = "X-API-Key"
+
+var versionPrefix = regexp.MustCompile(`^/v\d+/`)
+
+// Limiter enforces Rules on every request it sees.
+type Limiter struct {
+	backend Backend
+	rules   Rules
+	// apiKeys holds the hashes of rules.APIKeys and rules.UserAPIKeys.
+	apiKeys map[string]struct{}
+	// users maps the hashes of rules.UserAPIKeys to the id of the user they were issued to.
+	users  map[string]string
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func New(backend Backend, rules Rules, logger *slog.Logger) *Limiter {
+	apiKeys := make(map[string]struct{}, len(rules.APIKeys))
+	for _, apiKey := range rules.APIKeys {
+		apiKeys[hashAPIKey(apiKey)] = struct{}{}
+	}
+	users := map[string]string{}
+	for userId, keys := range rules.UserAPIKeys {
+		for _, apiKey := range keys {
+			apiKeys[hashAPIKey(apiKey)] = struct{}{}
+			users[hashAPIKey(apiKey)] = userId
+		}
+	}
+	return &Limiter{
+		backend: backend,
+		rules:   rules,
+		apiKeys: apiKeys,
+		users:   users,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Middleware takes a token for each request and rejects it with a RateLimited error once the client's bucket is
+// empty. Every limited response carries the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, plus
+// Retry-After when rejected.
+//
+// If the backend fails the request is let through: an unreachable rate limit store should not take the API down.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, policy, ok := l.policyFor(ctx)
+		if !ok {
+			ctx.Next()
+			return
+		}
+		client, ok := l.clientKey(ctx, policy.Keys)
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		result, err := l.backend.Take(ctx, name+"|"+client, policy, l.now())
+		if err != nil {
+			l.logger.WarnContext(ctx, "rate limit backend unavailable, allowing request", slog.Any("error", err))
+			ctx.Next()
+			return
+		}
+
+		header := ctx.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+		header.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+ceilSeconds(policy.Period))
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			_ = ctx.Error(cadence_errors.New(cadence_errors.RateLimited, "rate_limit.exceeded", "too many requests").
+				WithDetail("retry_after_seconds", int(math.Ceil(result.RetryAfter.Seconds()))))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// policyFor returns the bucket name and policy for the request's route, falling back to the default policy.
+func (l *Limiter) policyFor(ctx *gin.Context) (string, Policy, bool) {
+	if route := ctx.FullPath(); route != "" {
+		route = versionPrefix.ReplaceAllString(route, "/")
+		name := ctx.Request.Method + " " + route
+		if policy, ok := l.rules.Routes[name]; ok {
+			return name, policy, true
+		}
+	}
+	if l.rules.Default != nil {
+		return "default", *l.rules.Default, true
+	}
+	return "", Policy{}, false
+}
+
+// clientKey identifies the client by the first of keys present on the request. An API key only counts when it is one
+// of the issued Rules.APIKeys or Rules.UserAPIKeys; it is hashed so that it is never stored by the backend. A user is
+// only known from a key issued to them, never from the route, which anyone can fill in.
+func (l *Limiter) clientKey(ctx *gin.Context, keys []KeyKind) (string, bool) {
+	for _, kind := range keys {
+		switch kind {
+		case ByAPIKey:
+			if apiKey := ctx.GetHeader(APIKeyHeader); apiKey != "" {
+				hash := hashAPIKey(apiKey)
+				if _, issued := l.apiKeys[hash]; issued {
+					return "api_key:" + hash, true
+				}
+			}
+		case ByUser:
+			if apiKey := ctx.GetHeader(APIKeyHeader); apiKey != "" {
+				if userId, issued := l.users[hashAPIKey(apiKey)]; issued {
+					return "user:" + userId, true
+				}
+			}
+		case ByIP:
+			return "ip:" + ctx.ClientIP(), true
+		}
+	}
+	return "", false
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
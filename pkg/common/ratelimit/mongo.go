@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoBackend shares buckets between instances through a collection. Each take is a single findOneAndUpdate with an
+// update pipeline, so concurrent requests never spend the same token.
+//
+// Documents carry an expires_at date after which the bucket would be full again; a TTL index on it keeps the
+// collection small.
+type MongoBackend struct {
+	collection *mongo.Collection
+}
+
+func NewMongoBackend(collection *mongo.Collection) *MongoBackend {
+	return &MongoBackend{collection: collection}
+}
+
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func (b *MongoBackend) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	now = now.Truncate(time.Millisecond)
+	capacity := float64(policy.Limit)
+	ratePerMilli := policy.ratePerSecond() / 1000
+
+	// the refill is computed from the stored document inside the update, a missing document being a full bucket
+	refilled := bson.D{{Key: "$min", Value: bson.A{capacity, bson.D{{Key: "$add", Value: bson.A{
+		bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", capacity}}},
+		bson.D{{Key: "$multiply", Value: bson.A{
+			bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$subtract", Value: bson.A{
+				now,
+				bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", now}}},
+			}}}}}},
+			ratePerMilli,
+		}}},
+	}}}}}}
+	hasToken := bson.D{{Key: "$gte", Value: bson.A{"$refilled", 1}}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "refilled", Value: refilled}}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "allowed", Value: hasToken},
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{
+				hasToken,
+				bson.D{{Key: "$subtract", Value: bson.A{"$refilled", 1}}},
+				"$refilled",
+			}}}},
+			{Key: "updated_at", Value: now},
+			{Key: "expires_at", Value: now.Add(policy.Period)},
+		}}},
+		{{Key: "$unset", Value: "refilled"}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored mongoBucket
+	err := b.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// two instances upserted the same new bucket; the loser's retry updates the winner's document
+		err = b.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&stored)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return newResult(policy, stored.Tokens, stored.Allowed), nil
+}
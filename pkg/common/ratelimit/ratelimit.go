@@ -0,0 +1,193 @@
+// Package ratelimit limits requests with token buckets. Each client gets a bucket per policy that holds up to Limit
+// tokens and refills at Limit tokens per Period; a request spends one token and is rejected when none are left.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyKind names the client identity a bucket is keyed by.
+type KeyKind string
+
+const (
+	ByIP KeyKind = "ip"
+	// ByUser keys the bucket by the user an issued API key belongs to, so that all of a user's keys share it.
+	ByUser   KeyKind = "user"
+	ByAPIKey KeyKind = "api_key"
+)
+
+// Policy is a bucket's capacity and refill period. Keys lists the identities a client is bucketed by in order of
+// preference; the first one present on a request is used, so "api_key,ip" limits anonymous callers by address.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	Keys   []KeyKind
+}
+
+func (p Policy) String() string {
+	keys := make([]string, len(p.Keys))
+	for i, k := range p.Keys {
+		keys[i] = string(k)
+	}
+	return fmt.Sprintf("%d/%s per %s", p.Limit, p.Period, strings.Join(keys, ","))
+}
+
+// ratePerSecond is how many tokens are added to the bucket each second.
+func (p Policy) ratePerSecond() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Rules are the policies enforced by a Limiter. Routes are keyed by method and route template without the version
+// prefix, e.g. "POST /user", so every API version shares a client's bucket. Requests to other routes use Default,
+// when set.
+type Rules struct {
+	Default *Policy
+	Routes  map[string]Policy
+	// APIKeys are the keys issued to clients. Only these identify a client by ByAPIKey: any other X-API-Key is
+	// ignored, as a client could otherwise send a new key with every request to get a fresh bucket each time.
+	APIKeys []string
+	// UserAPIKeys are the keys issued to users, by user id. They identify their user by ByUser and, like APIKeys,
+	// their client by ByAPIKey.
+	UserAPIKeys map[string][]string
+}
+
+// Result describes a bucket after a request has tried to take a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token is available, zero when one already is.
+	RetryAfter time.Duration
+}
+
+// Backend stores buckets. Implementations must take tokens atomically so that instances sharing a backend enforce a
+// single limit.
+type Backend interface {
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// newResult describes a bucket holding tokens after a take that was allowed or not.
+func newResult(policy Policy, tokens float64, allowed bool) Result {
+	rate := policy.ratePerSecond()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Limit) - tokens) / rate),
+	}
+	if tokens < 1 {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// ParsePolicy parses a policy written as "<limit>/<period> [per <key>,...]", e.g. "30/1m per api_key,ip". Clients
+// are keyed by IP when no keys are given.
+func ParsePolicy(raw string) (Policy, error) {
+	spec, keys, hasKeys := strings.Cut(strings.TrimSpace(raw), " per ")
+
+	limit, period, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("expected <limit>/<period>, got %q", raw)
+	}
+	policy := Policy{Keys: []KeyKind{ByIP}}
+	var err error
+	if policy.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil || policy.Limit <= 0 {
+		return Policy{}, fmt.Errorf("expected a positive limit, got %q", limit)
+	}
+	if policy.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || policy.Period <= 0 {
+		return Policy{}, fmt.Errorf("expected a positive period such as 1m, got %q", period)
+	}
+
+	if hasKeys {
+		policy.Keys = nil
+		for _, key := range strings.Split(keys, ",") {
+			switch kind := KeyKind(strings.TrimSpace(key)); kind {
+			case ByIP, ByUser, ByAPIKey:
+				policy.Keys = append(policy.Keys, kind)
+			default:
+				return Policy{}, fmt.Errorf("unknown key %q, expected ip, user or api_key", key)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// ParseRoutes parses semicolon separated route policies such as "POST /user = 5/1m; GET /user/:email = 30/1m per
+// api_key,ip".
+func ParseRoutes(raw string) (map[string]Policy, error) {
+	routes := map[string]Policy{}
+	for _, entry := range strings.Split(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return nil, fmt.Errorf("expected <METHOD> <route> = <policy>, got %q", strings.TrimSpace(entry))
+		}
+		policy, err := ParsePolicy(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.TrimSpace(route), err)
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = policy
+	}
+	return routes, nil
+}
+
+// FormatRoutes is the inverse of ParseRoutes.
+func FormatRoutes(routes map[string]Policy) string {
+	entries := make([]string, 0, len(routes))
+	for route, policy := range routes {
+		entries = append(entries, route+" = "+policy.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "; ")
+}
+
+// ParseUserAPIKeys parses the API keys issued to each user, such as "64b7f3c2e4b0a1d2c3e4f5a6 = key-1,key-2;
+// 64b7f3c2e4b0a1d2c3e4f5a7 = key-3".
+func ParseUserAPIKeys(raw string) (map[string][]string, error) {
+	users := map[string][]string{}
+	for _, entry := range strings.Split(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		userId, keys, ok := strings.Cut(entry, "=")
+		userId = strings.TrimSpace(userId)
+		if !ok || userId == "" {
+			return nil, fmt.Errorf("expected <user id> = <api key>[,<api key>], got %q", strings.TrimSpace(entry))
+		}
+		for _, key := range strings.Split(keys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				users[userId] = append(users[userId], key)
+			}
+		}
+		if len(users[userId]) == 0 {
+			return nil, fmt.Errorf("%s: expected at least one api key", userId)
+		}
+	}
+	return users, nil
+}
+
+// FormatUserAPIKeys is the inverse of ParseUserAPIKeys.
+func FormatUserAPIKeys(users map[string][]string) string {
+	entries := make([]string, 0, len(users))
+	for userId, keys := range users {
+		entries = append(entries, userId+" = "+strings.Join(keys, ","))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "; ")
+}
@@ -0,0 +1,229 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/ratelimit"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type failingBackend struct{}
+
+func (failingBackend) Take(context.Context, string, ratelimit.Policy, time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+var _ = Describe("ParsePolicy", func() {
+	It("reads the limit, period and keys", func() {
+		policy, err := ratelimit.ParsePolicy("30/1m per api_key, ip")
+		Expect(err).To(BeNil())
+		Expect(policy).To(Equal(ratelimit.Policy{
+			Limit:  30,
+			Period: time.Minute,
+			Keys:   []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP},
+		}))
+	})
+
+	It("rejects unknown keys", func() {
+		_, err := ratelimit.ParsePolicy("30/1m per cookie")
+		Expect(err).To(MatchError(ContainSubstring("cookie")))
+	})
+
+	It("round trips routes", func() {
+		routes, err := ratelimit.ParseRoutes("POST /user = 5/1s; GET /user/:email = 60/1m per api_key,ip")
+		Expect(err).To(BeNil())
+		reparsed, err := ratelimit.ParseRoutes(ratelimit.FormatRoutes(routes))
+		Expect(err).To(BeNil())
+		Expect(reparsed).To(Equal(routes))
+	})
+})
+
+var _ = Describe("ParseUserAPIKeys", func() {
+	It("round trips each user's keys", func() {
+		users, err := ratelimit.ParseUserAPIKeys("user-1 = key-a, key-b; user-2 = key-c")
+		Expect(err).To(BeNil())
+		Expect(users).To(Equal(map[string][]string{"user-1": {"key-a", "key-b"}, "user-2": {"key-c"}}))
+		reparsed, err := ratelimit.ParseUserAPIKeys(ratelimit.FormatUserAPIKeys(users))
+		Expect(err).To(BeNil())
+		Expect(reparsed).To(Equal(users))
+	})
+
+	It("rejects users without keys", func() {
+		_, err := ratelimit.ParseUserAPIKeys("user-1 =")
+		Expect(err).To(MatchError(ContainSubstring("user-1")))
+	})
+})
+
+var _ = Describe("MemoryBackend", func() {
+	var (
+		backend *ratelimit.MemoryBackend
+		policy  ratelimit.Policy
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		backend = ratelimit.NewMemoryBackend()
+		policy = ratelimit.Policy{Limit: 2, Period: 10 * time.Second}
+		now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	take := func(at time.Time) ratelimit.Result {
+		result, err := backend.Take(context.TODO(), "client", policy, at)
+		Expect(err).To(BeNil())
+		return result
+	}
+
+	It("rejects requests once the bucket is empty", func() {
+		Expect(take(now).Remaining).To(Equal(1))
+		Expect(take(now).Allowed).To(BeTrue())
+
+		rejected := take(now)
+		Expect(rejected.Allowed).To(BeFalse())
+		Expect(rejected.Remaining).To(BeZero())
+		Expect(rejected.RetryAfter).To(Equal(5 * time.Second))
+		Expect(rejected.Reset).To(Equal(10 * time.Second))
+	})
+
+	It("refills at limit tokens per period", func() {
+		take(now)
+		take(now)
+
+		result := take(now.Add(5 * time.Second))
+		Expect(result.Allowed).To(BeTrue())
+		Expect(take(now.Add(5 * time.Second)).Allowed).To(BeFalse())
+	})
+
+	It("keeps a bucket per key", func() {
+		take(now)
+		take(now)
+
+		other, err := backend.Take(context.TODO(), "other", policy, now)
+		Expect(err).To(BeNil())
+		Expect(other.Allowed).To(BeTrue())
+	})
+})
+
+var _ = Describe("Limiter", func() {
+	var (
+		rules   ratelimit.Rules
+		backend ratelimit.Backend
+		router  *gin.Engine
+	)
+
+	BeforeEach(func() {
+		backend = ratelimit.NewMemoryBackend()
+		rules = ratelimit.Rules{
+			Default: &ratelimit.Policy{Limit: 100, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByIP}},
+			Routes: map[string]ratelimit.Policy{
+				"POST /user": {Limit: 1, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByAPIKey, ratelimit.ByIP}},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		router = gin.New()
+		router.Use(middleware.ErrorHandler(), ratelimit.New(backend, rules, logging.Discard()).Middleware())
+		for _, prefix := range []string{"", "/v1"} {
+			router.POST(prefix+"/user", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+			router.GET(prefix+"/user/:email", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		}
+	})
+
+	send := func(method, path, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, nil)
+		request.RemoteAddr = "203.0.113.7:1234"
+		if apiKey != "" {
+			request.Header.Set(ratelimit.APIKeyHeader, apiKey)
+		}
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	It("sets the RateLimit headers", func() {
+		w := send("GET", "/v1/user/jo@example.com", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("RateLimit-Limit")).To(Equal("100"))
+		Expect(w.Header().Get("RateLimit-Remaining")).To(Equal("99"))
+		Expect(w.Header().Get("RateLimit-Reset")).To(Equal("1"))
+		Expect(w.Header().Get("RateLimit-Policy")).To(Equal("100;w=60"))
+	})
+
+	It("rejects requests over the route's policy across api versions", func() {
+		Expect(send("POST", "/v1/user", "").Code).To(Equal(http.StatusCreated))
+
+		w := send("POST", "/user", "")
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get("Retry-After")).To(Equal("60"))
+		Expect(w.Body.String()).To(ContainSubstring("rate_limit.exceeded"))
+	})
+
+	It("limits clients with an unknown api key by their address", func() {
+		Expect(send("POST", "/v1/user", "made-up-1").Code).To(Equal(http.StatusCreated))
+		Expect(send("POST", "/v1/user", "made-up-2").Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	Context("api keys are issued", func() {
+		BeforeEach(func() {
+			rules.APIKeys = []string{"key-a", "key-b"}
+		})
+		It("prefers an issued api key over the address", func() {
+			Expect(send("POST", "/v1/user", "key-a").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "key-b").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "key-a").Code).To(Equal(http.StatusTooManyRequests))
+		})
+		It("still limits clients with an unknown api key by their address", func() {
+			Expect(send("POST", "/v1/user", "made-up-1").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "made-up-2").Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("api keys are issued to users", func() {
+		BeforeEach(func() {
+			rules.UserAPIKeys = map[string][]string{"user-1": {"key-a", "key-b"}, "user-2": {"key-c"}}
+			rules.Routes["POST /user"] = ratelimit.Policy{
+				Limit: 2, Period: time.Minute, Keys: []ratelimit.KeyKind{ratelimit.ByUser, ratelimit.ByIP},
+			}
+		})
+		It("shares a bucket between the keys of each user", func() {
+			Expect(send("POST", "/v1/user", "key-a").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "key-b").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "key-a").Code).To(Equal(http.StatusTooManyRequests))
+			Expect(send("POST", "/v1/user", "key-c").Code).To(Equal(http.StatusCreated))
+		})
+		It("limits clients without a user's key by their address", func() {
+			Expect(send("POST", "/v1/user", "made-up-1").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "made-up-2").Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("there is no default policy", func() {
+		BeforeEach(func() {
+			rules.Default = nil
+		})
+		It("does not limit other routes", func() {
+			w := send("GET", "/v1/user/jo@example.com", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("RateLimit-Limit")).To(BeEmpty())
+		})
+	})
+
+	Context("the backend is unavailable", func() {
+		BeforeEach(func() {
+			backend = failingBackend{}
+		})
+		It("lets requests through", func() {
+			Expect(send("POST", "/v1/user", "").Code).To(Equal(http.StatusCreated))
+			Expect(send("POST", "/v1/user", "").Code).To(Equal(http.StatusCreated))
+		})
+	})
+})
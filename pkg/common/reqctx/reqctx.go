@@ -0,0 +1,50 @@
+// Package reqctx stores request scoped values, such as the request id and the authenticated user, on a
+// context.Context so that they reach services, repositories and loggers without extra parameters.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIdHeader carries the request id between clients and this API.
+const RequestIdHeader = "X-Request-ID"
+
+type key int
+
+const (
+	requestIdKey key = iota
+	userIdKey
+)
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+// RequestId returns the id of the request being served, or "" outside of a request.
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey).(string)
+	return id
+}
+
+// NewRequestId generates an id for a request, or for a run of a background job so that its log lines can be
+// correlated like a request's.
+func NewRequestId() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read only fails if the OS entropy source is unavailable, in which case nothing else works either
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithUserId records the user the request is about. It is set by middleware.UserId from the route, or by the handler
+// once it has loaded the user.
+func WithUserId(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userIdKey, userId)
+}
+
+// UserId returns the user the request is about, or "" when it is about no user in particular.
+func UserId(ctx context.Context) string {
+	id, _ := ctx.Value(userIdKey).(string)
+	return id
+}
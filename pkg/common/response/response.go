@@ -0,0 +1,106 @@
+// Package response defines the JSON envelope shared by every resource controller.
+package response
+
+import (
+	"context"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+)
+
+const (
+	MessageSuccess = "success"
+	MessageError   = "error"
+)
+
+// Envelope wraps every JSON response body. Data is typed so that generated clients get a concrete schema per
+// endpoint instead of a free form map.
+type Envelope[T any] struct {
+	Status    int        `json:"status"`
+	Message   string     `json:"message"`
+	Data      T          `json:"data"`
+	Meta      *Meta      `json:"meta,omitempty"`
+	RequestId string     `json:"request_id,omitempty"`
+	Error     *ErrorBody `json:"error,omitempty"`
+}
+
+// Meta carries information about the response that is not part of the resource itself.
+type Meta struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+	Total  int64 `json:"total"`
+}
+
+type ErrorBody struct {
+	Code    string                      `json:"code,omitempty"`
+	Message string                      `json:"message"`
+	Details map[string]interface{}      `json:"details,omitempty"`
+	Fields  []cadence_errors.FieldError `json:"fields,omitempty"`
+}
+
+// Success wraps data, along with the id of the request being served on ctx.
+func Success[T any](ctx context.Context, status int, data T) Envelope[T] {
+	return Envelope[T]{
+		Status:    status,
+		Message:   MessageSuccess,
+		Data:      data,
+		RequestId: reqctx.RequestId(ctx),
+	}
+}
+
+// Paginated wraps a page of results along with the page that was requested and the total number of results.
+func Paginated[T any](ctx context.Context, status int, data []T, page pagination.Page, total int64) Envelope[[]T] {
+	if data == nil {
+		data = []T{}
+	}
+	return Envelope[[]T]{
+		Status:    status,
+		Message:   MessageSuccess,
+		Data:      data,
+		RequestId: reqctx.RequestId(ctx),
+		Meta: &Meta{
+			Pagination: &Pagination{
+				Limit:  page.Limit,
+				Offset: page.Offset,
+				Total:  total,
+			},
+		},
+	}
+}
+
+func Failure(ctx context.Context, status int, body ErrorBody) Envelope[any] {
+	return Envelope[any]{
+		Status:    status,
+		Message:   MessageError,
+		Error:     &body,
+		RequestId: reqctx.RequestId(ctx),
+	}
+}
+
+// Legacy is the body of the deprecated unversioned routes, {"status", "message", "data": {"data"}}, which old clients
+// parse. Errors carry their message in the inner data along with their code and details.
+type Legacy[T any] struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    LegacyData[T] `json:"data"`
+}
+
+type LegacyData[T any] struct {
+	Data    T                      `json:"data"`
+	Code    string                 `json:"code,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// LegacySuccess wraps data in the body of the unversioned routes.
+func LegacySuccess[T any](status int, data T) Legacy[T] {
+	return Legacy[T]{
+		Status:  status,
+		Message: MessageSuccess,
+		Data:    LegacyData[T]{Data: data},
+	}
+}
@@ -0,0 +1,46 @@
+// Package server runs the HTTP server as part of the application lifecycle.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Register adds srv to the lifecycle. The listener is opened during start so that bind errors fail startup, and stop
+// gracefully drains in-flight requests until the stop context expires. The server speaks TLS, and HTTP/2 over it, when
+// srv.TLSConfig is set.
+func Register(l *lifecycle.Lifecycle, srv *http.Server) {
+	l.Append(lifecycle.Hook{
+		Name: "http server",
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", srv.Addr, err)
+			}
+			go func() {
+				serve := srv.Serve
+				if srv.TLSConfig != nil {
+					serve = func(listener net.Listener) error { return srv.ServeTLS(listener, "", "") }
+				}
+				if err := serve(listener); !errors.Is(err, http.ErrServerClosed) {
+					l.Fail(fmt.Errorf("http server stopped: %w", err))
+				}
+			}()
+			return nil
+		},
+		Stop: srv.Shutdown,
+	})
+}
+
+// H2C lets handler serve HTTP/2 without TLS, for internal callers that connect with prior knowledge or upgrade from
+// HTTP/1.1. TLS connections are unaffected and negotiate HTTP/2 as usual.
+func H2C(handler http.Handler) http.Handler {
+	return h2c.NewHandler(handler, &http2.Server{})
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSFiles locates the server's key pair and, for mutual TLS, the CA that signs client certificates.
+type TLSFiles struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS when set, rejecting clients without a certificate signed by this CA.
+	ClientCAFile string
+	MinVersion   uint16
+}
+
+// CertReloader serves the certificates in TLSFiles and reloads them when the files change, so that renewed
+// certificates are picked up without a restart.
+type CertReloader struct {
+	files  TLSFiles
+	logger *slog.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes []time.Time
+}
+
+// NewCertReloader loads the files, failing if they are missing or invalid.
+func NewCertReloader(files TLSFiles, logger *slog.Logger) (*CertReloader, error) {
+	r := &CertReloader{files: files, logger: logger}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) paths() []string {
+	paths := []string{r.files.CertFile, r.files.KeyFile}
+	if r.files.ClientCAFile != "" {
+		paths = append(paths, r.files.ClientCAFile)
+	}
+	return paths
+}
+
+func (r *CertReloader) load() error {
+	modTimes, err := statAll(r.paths())
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+	var clientCA *x509.CertPool
+	if r.files.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.files.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client ca: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return errors.New("failed to load client ca: no certificates found")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.modTimes = modTimes
+	return nil
+}
+
+func statAll(paths []string) ([]time.Time, error) {
+	modTimes := make([]time.Time, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls file: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// changed reports whether any file has been modified since it was last loaded.
+func (r *CertReloader) changed() bool {
+	modTimes, err := statAll(r.paths())
+	if err != nil {
+		// a renewal that replaces files one at a time can briefly leave one missing; try again next tick
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch polls the files every interval until ctx is cancelled. A reload that fails keeps the previous certificates
+// in use, since a half written renewal should not take the server down.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.logger.ErrorContext(ctx, "failed to reload tls certificates", slog.Any("error", err))
+				continue
+			}
+			r.logger.InfoContext(ctx, "reloaded tls certificates")
+		}
+	}
+}
+
+// Config returns a tls.Config that uses the most recently loaded files for every handshake.
+func (r *CertReloader) Config() *tls.Config {
+	minVersion := r.files.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	base := &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	// http.Server.ServeTLS looks for a certificate source before the first handshake
+	base.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.cert, nil
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.GetCertificate = nil
+		config.Certificates = []tls.Certificate{*r.cert}
+		if r.clientCA != nil {
+			config.ClientCAs = r.clientCA
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return config, nil
+	}
+	return base
+}
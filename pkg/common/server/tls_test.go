@@ -0,0 +1,197 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/server"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/http2"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+// issue creates a certificate signed by ca, or a self-signed CA when ca is nil.
+func issue(ca *authority, commonName string, usage x509.ExtKeyUsage) (*authority, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	parent, signer := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	Expect(err).To(BeNil())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).To(BeNil())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+	return &authority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+var _ = Describe("CertReloader", func() {
+	var (
+		dir      string
+		ca       *authority
+		files    server.TLSFiles
+		reloader *server.CertReloader
+		srv      *httptest.Server
+		pool     *x509.CertPool
+	)
+
+	writeServerCert := func(commonName string, modTime time.Time) {
+		cert, key := issue(ca, commonName, x509.ExtKeyUsageServerAuth)
+		Expect(os.WriteFile(files.CertFile, cert.pem, 0o600)).To(Succeed())
+		Expect(os.WriteFile(files.KeyFile, key, 0o600)).To(Succeed())
+		Expect(os.Chtimes(files.CertFile, modTime, modTime)).To(Succeed())
+		Expect(os.Chtimes(files.KeyFile, modTime, modTime)).To(Succeed())
+	}
+
+	servedName := func(clientCerts ...tls.Certificate) (string, error) {
+		conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), &tls.Config{RootCAs: pool, Certificates: clientCerts})
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		// the server only rejects a missing client certificate after the handshake, on first use
+		if _, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err == nil {
+			_, err = conn.Read(make([]byte, 1))
+		}
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, err
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		ca, _ = issue(nil, "test ca", x509.ExtKeyUsageAny)
+		pool = x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		files = server.TLSFiles{
+			CertFile: filepath.Join(dir, "tls.crt"),
+			KeyFile:  filepath.Join(dir, "tls.key"),
+		}
+		writeServerCert("first", time.Now().Add(-time.Minute))
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		reloader, err = server.NewCertReloader(files, logging.Discard())
+		Expect(err).To(BeNil())
+
+		srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		srv.TLS = reloader.Config()
+		srv.StartTLS()
+		DeferCleanup(srv.Close)
+	})
+
+	It("picks up renewed certificates", func() {
+		Expect(servedName()).To(Equal("first"))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan error)
+		go func() { done <- reloader.Watch(ctx, 10*time.Millisecond) }()
+		writeServerCert("second", time.Now())
+
+		Eventually(servedName).Should(Equal("second"))
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("keeps serving the previous certificate when a renewal is invalid", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go func() { _ = reloader.Watch(ctx, 10*time.Millisecond) }()
+		Expect(os.WriteFile(files.KeyFile, []byte("not a key"), 0o600)).To(Succeed())
+
+		Consistently(servedName, 100*time.Millisecond).Should(Equal("first"))
+	})
+
+	Context("a client CA is configured", func() {
+		var clientCert tls.Certificate
+
+		BeforeEach(func() {
+			files.ClientCAFile = filepath.Join(dir, "ca.crt")
+			Expect(os.WriteFile(files.ClientCAFile, ca.pem, 0o600)).To(Succeed())
+
+			cert, key := issue(ca, "internal caller", x509.ExtKeyUsageClientAuth)
+			var err error
+			clientCert, err = tls.X509KeyPair(cert.pem, key)
+			Expect(err).To(BeNil())
+		})
+		It("only accepts clients with a certificate it signed", func() {
+			_, err := servedName()
+			Expect(err).NotTo(BeNil())
+			Expect(servedName(clientCert)).To(Equal("first"))
+
+			stranger, key := issue(nil, "stranger", x509.ExtKeyUsageClientAuth)
+			strangerCert, err := tls.X509KeyPair(stranger.pem, key)
+			Expect(err).To(BeNil())
+			_, err = servedName(strangerCert)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	It("fails when the files are missing", func() {
+		files.CertFile = filepath.Join(dir, "missing.crt")
+		_, err := server.NewCertReloader(files, logging.Discard())
+		Expect(err).To(MatchError(ContainSubstring("missing.crt")))
+	})
+})
+
+var _ = Describe("H2C", func() {
+	It("serves HTTP/2 without TLS", func() {
+		srv := httptest.NewServer(server.H2C(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		})))
+		defer srv.Close()
+
+		client := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}}
+		response, err := client.Get(srv.URL)
+		Expect(err).To(BeNil())
+		defer response.Body.Close()
+		Expect(response.ProtoMajor).To(Equal(2))
+	})
+})
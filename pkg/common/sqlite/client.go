@@ -0,0 +1,46 @@
+// Package sqlite opens an embedded SQLite database file and keeps its schema up to date, letting the API run as a
+// single binary with a single data file.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/lifecycle"
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the database file at path, creating it if it does not exist. The file is used in WAL mode so that reads
+// are not blocked by a writer, and writers wait for the lock instead of failing while another write is in progress.
+func Open(path string) (*sql.DB, error) {
+	query := url.Values{}
+	// the busy timeout comes first, as switching a new file to WAL needs the lock that other connections may hold
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+// Hook applies pending migrations when the lifecycle starts and closes the database when it stops.
+func Hook(db *sql.DB) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "sqlite",
+		Start: func(ctx context.Context) error {
+			if err := db.PingContext(ctx); err != nil {
+				return fmt.Errorf("failed to open sqlite database: %w", err)
+			}
+			return Migrate(ctx, db)
+		},
+		Stop: func(context.Context) error {
+			return db.Close()
+		},
+	}
+}
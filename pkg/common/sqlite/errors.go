@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var translator = storage.Translator{Engine: "sqlite", Container: "table", Classify: classify}
+
+func classify(err error) storage.Failure {
+	var sqliteErr *sqlite.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return storage.Missing
+	case errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY):
+		return storage.Duplicate
+	default:
+		return storage.Failed
+	}
+}
+
+// TranslateError converts a database/sql or SQLite error into a cadence_errors.Error. A nil error is returned
+// unchanged.
+func TranslateError(err error) error {
+	return translator.TranslateError(err)
+}
+
+// TranslateAndLog translates err like TranslateError and logs the failures that are hidden from clients.
+func TranslateAndLog(ctx context.Context, logger *slog.Logger, table, operation string, err error) error {
+	return translator.TranslateAndLog(ctx, logger, table, operation, err)
+}
@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/health"
+)
+
+// HealthChecker pings the database.
+func HealthChecker(db *sql.DB) health.Checker {
+	return health.CheckerFunc("sqlite", func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlmigrate"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies every embedded migration that has not been applied yet, recording them in schema_migrations. Each
+// migration runs in its own immediate transaction, which holds the database's write lock, and is skipped if another
+// process applied it while this one waited for the lock.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	pending, err := sqlmigrate.Load(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to load sqlite migrations: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	for _, m := range pending {
+		if err = apply(ctx, db, m); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, m sqlmigrate.Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var applied int
+	err = tx.QueryRowContext(ctx, "SELECT count(*) FROM schema_migrations WHERE version = ?", m.Version).Scan(&applied)
+	if err != nil || applied > 0 {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,11 @@
+-- repeating_days holds a JSON array, or NULL when the habit has none
+CREATE TABLE habits (
+    id             TEXT    PRIMARY KEY,
+    user_id        TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    cadence        INTEGER NOT NULL,
+    repeating_days TEXT,
+    streak         INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX habits_user_id_id ON habits (user_id, id);
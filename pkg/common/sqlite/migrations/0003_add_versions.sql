@@ -0,0 +1,3 @@
+-- rows written before versions existed start at the first version
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE habits ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
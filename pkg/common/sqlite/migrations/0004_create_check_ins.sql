@@ -0,0 +1,11 @@
+-- date holds the day as YYYY-MM-DD, which sorts chronologically
+CREATE TABLE check_ins (
+    id       TEXT    PRIMARY KEY,
+    habit_id TEXT    NOT NULL,
+    user_id  TEXT    NOT NULL,
+    date     TEXT    NOT NULL,
+    streak   INTEGER NOT NULL,
+    UNIQUE (habit_id, date)
+);
+
+CREATE INDEX check_ins_user_id ON check_ins (user_id);
@@ -0,0 +1,6 @@
+-- deleted_at holds a UTC time formatted to sort chronologically, or NULL outside the trash
+ALTER TABLE habits ADD COLUMN deleted_at TEXT;
+ALTER TABLE check_ins ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX habits_deleted_at ON habits (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX check_ins_deleted_at ON check_ins (deleted_at) WHERE deleted_at IS NOT NULL;
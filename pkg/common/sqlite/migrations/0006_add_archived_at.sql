@@ -0,0 +1,2 @@
+-- archived_at is formatted like deleted_at
+ALTER TABLE habits ADD COLUMN archived_at TEXT;
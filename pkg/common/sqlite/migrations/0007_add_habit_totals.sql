@@ -0,0 +1,8 @@
+-- habits keep their best streak and check-in count so that stats read one row per habit; existing habits take them
+-- from their check-ins, including those in the trash along with their habit
+ALTER TABLE habits ADD COLUMN best_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE habits ADD COLUMN check_ins INTEGER NOT NULL DEFAULT 0;
+
+UPDATE habits SET
+    best_streak = (SELECT coalesce(max(streak), 0) FROM check_ins WHERE check_ins.habit_id = habits.id),
+    check_ins = (SELECT count(*) FROM check_ins WHERE check_ins.habit_id = habits.id);
@@ -0,0 +1,75 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite/sqlitetest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TranslateError", func() {
+	DescribeTable("maps driver errors to kinds",
+		func(err error, kind cadence_errors.Kind, code string) {
+			translated, ok := cadence_errors.As(sqlite.TranslateError(err))
+			Expect(ok).To(BeTrue())
+			Expect(translated.Kind).To(Equal(kind))
+			Expect(translated.Code).To(Equal(code))
+			Expect(errors.Is(translated, err)).To(BeTrue())
+		},
+		Entry("no rows", fmt.Errorf("scan: %w", sql.ErrNoRows), cadence_errors.NotFound, ""),
+		Entry("cancelled", context.Canceled, cadence_errors.Internal, "storage.timeout"),
+		Entry("anything else", errors.New("disk I/O error"), cadence_errors.Internal, "storage.error"),
+	)
+
+	It("maps unique violations to conflicts", func() {
+		db := sqlitetest.Database()
+		_, err := db.Exec("INSERT INTO users (id, email) VALUES ('a', 'test@test.com')")
+		Expect(err).To(BeNil())
+		_, err = db.Exec("INSERT INTO users (id, email) VALUES ('b', 'test@test.com')")
+		Expect(cadence_errors.KindOf(sqlite.TranslateError(err))).To(Equal(cadence_errors.Conflict))
+	})
+
+	It("leaves nil alone", func() {
+		Expect(sqlite.TranslateError(nil)).To(BeNil())
+	})
+})
+
+var _ = Describe("Migrate", func() {
+	It("is safe to run again and records each migration once", func() {
+		db := sqlitetest.Database()
+		count := func() (applied int) {
+			Expect(db.QueryRow("SELECT count(*) FROM schema_migrations").Scan(&applied)).To(Succeed())
+			return applied
+		}
+		applied := count()
+		Expect(applied).To(BeNumerically(">", 0))
+
+		Expect(sqlite.Migrate(context.TODO(), db)).To(Succeed())
+		Expect(count()).To(Equal(applied))
+	})
+
+	It("applies each migration once when instances start together", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cadence.db")
+		errs := make(chan error, 4)
+		for i := 0; i < cap(errs); i++ {
+			go func() {
+				db, err := sqlite.Open(path)
+				if err == nil {
+					err = sqlite.Migrate(context.TODO(), db)
+					_ = db.Close()
+				}
+				errs <- err
+			}()
+		}
+		for i := 0; i < cap(errs); i++ {
+			Expect(<-errs).To(Succeed())
+		}
+	})
+})
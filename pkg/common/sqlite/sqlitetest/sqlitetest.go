@@ -0,0 +1,22 @@
+// Package sqlitetest gives Ginkgo specs a throwaway, migrated SQLite database.
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Database opens a migrated database in a file under the spec's temporary directory and closes it after the spec.
+func Database() *sql.DB {
+	db, err := sqlite.Open(filepath.Join(GinkgoT().TempDir(), "cadence.db"))
+	Expect(err).To(BeNil())
+	DeferCleanup(db.Close)
+
+	Expect(sqlite.Migrate(context.Background(), db)).To(Succeed())
+	return db
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// transactionAttempts bounds how many times a transaction that could not get the database lock is run.
+const transactionAttempts = 3
+
+// Queryer is implemented by both *sql.DB and *sql.Tx, letting repositories run the same statements inside and
+// outside a transaction.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction a TransactionRunner stored in ctx, or db when ctx is not part of a transaction.
+func Conn(ctx context.Context, db *sql.DB) Queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// TransactionRunner runs units of work in transactions on db. Repositories take part by running their statements on
+// Conn. Opened with Open, every transaction takes the write lock when it begins, so transactions run one at a time.
+type TransactionRunner struct {
+	db *sql.DB
+}
+
+func NewTransactionRunner(db *sql.DB) *TransactionRunner {
+	return &TransactionRunner{db: db}
+}
+
+var _ transaction.Runner = (*TransactionRunner)(nil)
+
+// Run runs fn in a transaction, running it again when the database stays locked for longer than the busy timeout.
+func (r *TransactionRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		err := r.run(ctx, fn)
+		if err == nil || attempt == transactionAttempts || !busy(err) {
+			return err
+		}
+	}
+}
+
+func (r *TransactionRunner) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return TranslateError(err)
+	}
+	return nil
+}
+
+// busy reports whether err, or an error it wraps, means the database was locked by another connection.
+func busy(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+}
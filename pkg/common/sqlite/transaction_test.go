@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite/sqlitetest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TransactionRunner", func() {
+	var (
+		db     *sql.DB
+		target *sqlite.TransactionRunner
+		insert func(ctx context.Context, id string) error
+		count  func() int
+	)
+
+	BeforeEach(func() {
+		db = sqlitetest.Database()
+		target = sqlite.NewTransactionRunner(db)
+		insert = func(ctx context.Context, id string) error {
+			_, err := sqlite.Conn(ctx, db).ExecContext(ctx, "INSERT INTO users (id, email) VALUES (?, ?)", id, id+"@test.com")
+			return err
+		}
+		count = func() (users int) {
+			Expect(db.QueryRow("SELECT count(*) FROM users").Scan(&users)).To(Succeed())
+			return users
+		}
+	})
+
+	It("commits the writes made through Conn", func() {
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(sqlite.Conn(ctx, db)).NotTo(BeIdenticalTo(db))
+			Expect(insert(ctx, "a")).To(Succeed())
+			return insert(ctx, "b")
+		})
+		Expect(err).To(BeNil())
+		Expect(count()).To(Equal(2))
+	})
+
+	It("rolls every write back when fn fails", func() {
+		boom := errors.New("boom")
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(insert(ctx, "a")).To(Succeed())
+			return boom
+		})
+		Expect(err).To(MatchError(boom))
+		Expect(count()).To(BeZero())
+	})
+
+	It("joins the surrounding transaction when nested", func() {
+		err := target.Run(context.TODO(), func(ctx context.Context) error {
+			Expect(target.Run(ctx, func(ctx context.Context) error { return insert(ctx, "a") })).To(Succeed())
+			return errors.New("boom")
+		})
+		Expect(err).NotTo(BeNil())
+		Expect(count()).To(BeZero())
+	})
+
+	It("uses the database outside a transaction", func() {
+		Expect(sqlite.Conn(context.TODO(), db)).To(BeIdenticalTo(db))
+	})
+})
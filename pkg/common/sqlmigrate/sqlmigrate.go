@@ -0,0 +1,44 @@
+// Package sqlmigrate reads the SQL migrations that the PostgreSQL and SQLite packages embed. Each package applies them
+// with its own locking and bookkeeping.
+package sqlmigrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration is one file of SQL statements, applied as a whole.
+type Migration struct {
+	Version int
+	// Name is the file name without its extension, e.g. 0001_create_users.
+	Name string
+	SQL  string
+}
+
+// Load reads the files in dir, named <version>_<name>.sql, in version order.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	loaded := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no version prefix", entry.Name())
+		}
+		sql, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, Migration{Version: version, Name: name, SQL: string(sql)})
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Version < loaded[j].Version })
+	return loaded, nil
+}
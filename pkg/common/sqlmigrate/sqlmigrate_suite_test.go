@@ -0,0 +1,13 @@
+package sqlmigrate_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSqlmigrate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sqlmigrate Suite")
+}
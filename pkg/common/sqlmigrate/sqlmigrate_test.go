@@ -0,0 +1,28 @@
+package sqlmigrate_test
+
+import (
+	"testing/fstest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlmigrate"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	It("reads the migrations in version order", func() {
+		fsys := fstest.MapFS{
+			"migrations/0010_add_index.sql":    {Data: []byte("CREATE INDEX")},
+			"migrations/0002_create_users.sql": {Data: []byte("CREATE TABLE")},
+		}
+		Expect(sqlmigrate.Load(fsys, "migrations")).To(Equal([]sqlmigrate.Migration{
+			{Version: 2, Name: "0002_create_users", SQL: "CREATE TABLE"},
+			{Version: 10, Name: "0010_add_index", SQL: "CREATE INDEX"},
+		}))
+	})
+
+	It("rejects files without a version prefix", func() {
+		fsys := fstest.MapFS{"migrations/create_users.sql": {Data: []byte("CREATE TABLE")}}
+		_, err := sqlmigrate.Load(fsys, "migrations")
+		Expect(err).To(MatchError(ContainSubstring("create_users.sql has no version prefix")))
+	})
+})
@@ -0,0 +1,79 @@
+// Package storage translates errors from the storage engines into cadence_errors, so that services cannot tell the
+// engines apart. Each engine only says what its driver's errors mean.
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+)
+
+// Failure is what a driver error means to the layers above the repositories.
+type Failure uint8
+
+const (
+	// Failed covers every error the engine does not recognise.
+	Failed Failure = iota
+	Missing
+	Duplicate
+	TimedOut
+)
+
+// Classifier recognises the errors of one storage engine's driver.
+type Classifier func(err error) Failure
+
+// Translator converts the errors of one storage engine.
+type Translator struct {
+	// Engine names the storage engine in log messages, e.g. mongo.
+	Engine string
+	// Container is the log attribute naming what an operation worked on, e.g. collection or table.
+	Container string
+	Classify  Classifier
+}
+
+// TranslateError converts err into a cadence_errors.Error. Cancelled and expired contexts are timeouts whatever the
+// engine. A nil error is returned unchanged.
+func (t Translator) TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	failure := t.Classify(err)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		failure = TimedOut
+	}
+	switch failure {
+	case Missing:
+		return cadence_errors.Wrap(err, cadence_errors.NotFound, "", "not found")
+	case Duplicate:
+		return cadence_errors.Wrap(err, cadence_errors.Conflict, "", "duplicate key")
+	case TimedOut:
+		return cadence_errors.Wrap(err, cadence_errors.Internal, "storage.timeout", "storage operation timed out")
+	default:
+		return cadence_errors.Wrap(err, cadence_errors.Internal, "storage.error", "storage operation failed")
+	}
+}
+
+// TranslateAndLog translates err like TranslateError and logs failures that callers cannot act on, such as lost
+// connections, so that the underlying driver error is recorded even though it is hidden from clients.
+func (t Translator) TranslateAndLog(ctx context.Context, logger *slog.Logger, container, operation string, err error) error {
+	translated := t.TranslateError(err)
+	if translated != nil && cadence_errors.KindOf(translated) == cadence_errors.Internal {
+		logger.ErrorContext(ctx, t.Engine+" operation failed",
+			slog.String(t.Container, container),
+			slog.String("operation", operation),
+			slog.Any("error", err),
+		)
+	}
+	return translated
+}
+
+// CheckContext fails like a storage operation would when ctx is already done. It is for engines that never wait on a
+// driver, such as the in-memory repositories.
+func CheckContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return cadence_errors.Wrap(err, cadence_errors.Internal, "storage.timeout", "storage operation timed out")
+	}
+	return nil
+}
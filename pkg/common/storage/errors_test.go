@@ -0,0 +1,73 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	errNoRows    = errors.New("no rows")
+	errDuplicate = errors.New("duplicate")
+)
+
+var translator = storage.Translator{
+	Engine:    "test",
+	Container: "table",
+	Classify: func(err error) storage.Failure {
+		switch {
+		case errors.Is(err, errNoRows):
+			return storage.Missing
+		case errors.Is(err, errDuplicate):
+			return storage.Duplicate
+		default:
+			return storage.Failed
+		}
+	},
+}
+
+var _ = Describe("Translator", func() {
+	It("maps the engine's classification to cadence_errors kinds", func() {
+		Expect(translator.TranslateError(errNoRows)).To(MatchError(cadence_errors.ErrNotFound))
+		Expect(translator.TranslateError(errDuplicate)).To(MatchError(cadence_errors.ErrConflict))
+		Expect(translator.TranslateError(errors.New("connection reset"))).
+			To(MatchError(cadence_errors.New(cadence_errors.Internal, "storage.error", "")))
+		Expect(translator.TranslateError(nil)).To(BeNil())
+	})
+
+	It("treats a done context as a timeout whatever the engine says", func() {
+		err := fmt.Errorf("query: %w", context.DeadlineExceeded)
+		Expect(translator.TranslateError(err)).To(MatchError(cadence_errors.New(cadence_errors.Internal, "storage.timeout", "")))
+	})
+
+	It("logs only the failures hidden from clients", func() {
+		var out bytes.Buffer
+		logger, err := logging.New(&out, "info", logging.FormatJSON)
+		Expect(err).To(BeNil())
+
+		_ = translator.TranslateAndLog(context.TODO(), logger, "habits", "find habit", errNoRows)
+		Expect(out.String()).To(BeEmpty())
+
+		_ = translator.TranslateAndLog(context.TODO(), logger, "habits", "find habit", errors.New("connection reset"))
+		Expect(out.String()).To(ContainSubstring(`"msg":"test operation failed"`))
+		Expect(out.String()).To(ContainSubstring(`"table":"habits"`))
+	})
+})
+
+var _ = Describe("CheckContext", func() {
+	It("fails with a timeout once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		Expect(storage.CheckContext(ctx)).To(BeNil())
+
+		cancel()
+		Expect(storage.CheckContext(ctx)).To(MatchError(cadence_errors.New(cadence_errors.Internal, "storage.timeout", "")))
+		Expect(storage.CheckContext(ctx)).To(MatchError(context.Canceled))
+	})
+})
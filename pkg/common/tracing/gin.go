@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the trace from an incoming traceparent header when
+// there is one. Spans are named by route template, like the metrics, so /user/:email is one operation rather than
+// one per address. The request id is recorded so a trace can be found from a client's error.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+
+		route := ctx.FullPath()
+		name := fmt.Sprintf("%s %s", ctx.Request.Method, route)
+		if route == "" {
+			name = ctx.Request.Method
+		}
+		spanCtx, span := otel.Tracer(instrumentationName).Start(parent, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(ctx.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		if requestId := reqctx.RequestId(ctx.Request.Context()); requestId != "" {
+			span.SetAttributes(attribute.String("request.id", requestId))
+		}
+
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(ctx.Errors) > 0 {
+			recordKind(span, ctx.Errors.Last().Err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CommandMonitor returns a driver monitor that wraps every Mongo command in a client span. The driver calls Started
+// with the operation's context, so each span is a child of the service or request span that issued the command.
+func CommandMonitor() *event.CommandMonitor {
+	var inflight sync.Map
+
+	// the failure is left off the span since it may quote the document, such as the duplicated key of an insert
+	finish := func(requestId int64, failed bool) {
+		value, ok := inflight.LoadAndDelete(requestId)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failed {
+			span.SetStatus(codes.Error, "command failed")
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection := mongodb.CollectionOf(e)
+			_, span := otel.Tracer(instrumentationName).Start(ctx, e.CommandName+" "+collection,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBOperationName(e.CommandName),
+					semconv.DBCollectionName(collection),
+				),
+			)
+			inflight.Store(e.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, true)
+		},
+	}
+}
@@ -0,0 +1,104 @@
+// Package tracing configures OpenTelemetry and instruments the HTTP and Mongo layers. Spans are propagated between
+// services with W3C trace context headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	ServiceName string
+	// Exporter is none, stdout or otlp.
+	Exporter string
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// SampleRatio is the fraction of new traces that are recorded. Traces started upstream keep their decision.
+	SampleRatio float64
+}
+
+// instrumentationName identifies the spans created by this module.
+const instrumentationName = "github.com/alexander-littleton/cadence-api"
+
+// Setup creates the tracer provider for cfg and installs it, along with the W3C trace context propagator, as the
+// global provider used by Start, Middleware and CommandMonitor. The returned shutdown function flushes buffered spans.
+func Setup(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span from the global tracer provider. Spans are children of any span already in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks the span as failed with the kind and code of err, if any, and ends it. The message is left out since it
+// may carry personal data such as an email.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, recordKind(span, err))
+	}
+	span.End()
+}
+
+// recordKind sets the kind and code of err as attributes of span and returns the kind.
+func recordKind(span trace.Span, err error) string {
+	kind := cadence_errors.KindOf(err).String()
+	span.SetAttributes(attribute.String("error.type", kind))
+	if e, ok := cadence_errors.As(err); ok && e.Code != "" {
+		span.SetAttributes(attribute.String("error.code", e.Code))
+	}
+	return kind
+}
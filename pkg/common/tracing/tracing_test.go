@@ -0,0 +1,149 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/tracing"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Tracing", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+
+	spanNamed := func(name string) sdktrace.ReadOnlySpan {
+		for _, span := range recorder.Ended() {
+			if span.Name() == name {
+				return span
+			}
+		}
+		Fail("no span named " + name)
+		return nil
+	}
+
+	Describe("Middleware", func() {
+		var (
+			router  *gin.Engine
+			request *http.Request
+		)
+
+		BeforeEach(func() {
+			router = gin.New()
+			router.Use(tracing.Middleware())
+			router.GET("/user/:email", func(ctx *gin.Context) {
+				_, span := tracing.Start(ctx.Request.Context(), "user.Service/GetUserByEmail")
+				span.End()
+				ctx.Status(http.StatusInternalServerError)
+			})
+			request, _ = http.NewRequest("GET", "/user/jo@example.com", nil)
+		})
+
+		JustBeforeEach(func() {
+			router.ServeHTTP(httptest.NewRecorder(), request)
+		})
+
+		It("names the span by route template and parents spans started by handlers", func() {
+			server := spanNamed("GET /user/:email")
+			Expect(server.SpanKind()).To(Equal(trace.SpanKindServer))
+			Expect(server.Status().Code).To(Equal(codes.Error))
+			Expect(spanNamed("user.Service/GetUserByEmail").Parent().SpanID()).To(Equal(server.SpanContext().SpanID()))
+		})
+
+		Context("the request carries a traceparent", func() {
+			BeforeEach(func() {
+				request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			})
+			It("continues the caller's trace", func() {
+				server := spanNamed("GET /user/:email")
+				Expect(server.SpanContext().TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+				Expect(server.Parent().SpanID().String()).To(Equal("00f067aa0ba902b7"))
+				Expect(server.Parent().IsRemote()).To(BeTrue())
+			})
+		})
+
+		Context("the handler fails", func() {
+			BeforeEach(func() {
+				router.GET("/user/:email/habits", func(ctx *gin.Context) {
+					_ = ctx.Error(cadence_errors.New(cadence_errors.NotFound, "user.not_found", "no user jo@example.com"))
+					ctx.Status(http.StatusNotFound)
+				})
+				request, _ = http.NewRequest("GET", "/user/jo@example.com/habits", nil)
+			})
+			It("records the kind of the error without its message", func() {
+				server := spanNamed("GET /user/:email/habits")
+				Expect(server.Attributes()).To(ContainElement(attribute.String("error.type", "not_found")))
+				Expect(server.Events()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("CommandMonitor", func() {
+		It("creates a child span for each command", func() {
+			ctx, parent := tracing.Start(context.TODO(), "user.Service/CreateUser")
+			monitor := tracing.CommandMonitor()
+			command, _ := bson.Marshal(bson.D{{Key: "insert", Value: "users"}})
+			monitor.Started(ctx, &event.CommandStartedEvent{
+				Command:      command,
+				DatabaseName: "golangAPI",
+				CommandName:  "insert",
+				RequestID:    7,
+			})
+			monitor.Failed(ctx, &event.CommandFailedEvent{
+				CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", RequestID: 7},
+				Failure:              "E11000 duplicate key error",
+			})
+			parent.End()
+
+			span := spanNamed("insert users")
+			Expect(span.SpanKind()).To(Equal(trace.SpanKindClient))
+			Expect(span.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+			Expect(span.Status().Code).To(Equal(codes.Error))
+			Expect(span.Status().Description).NotTo(ContainSubstring("E11000"))
+		})
+	})
+
+	Describe("End", func() {
+		It("records the kind and code of the error without its message", func() {
+			_, span := tracing.Start(context.TODO(), "failing")
+			tracing.End(span, cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "jo@example.com is taken"))
+
+			ended := spanNamed("failing")
+			Expect(ended.Status().Code).To(Equal(codes.Error))
+			Expect(ended.Status().Description).To(Equal("conflict"))
+			Expect(ended.Attributes()).To(ContainElements(
+				attribute.String("error.type", "conflict"),
+				attribute.String("error.code", "user.email_taken"),
+			))
+			Expect(ended.Events()).To(BeEmpty())
+		})
+
+		It("reports errors without a kind as internal", func() {
+			_, span := tracing.Start(context.TODO(), "failing")
+			tracing.End(span, errors.New("dial tcp: jo@example.com"))
+
+			ended := spanNamed("failing")
+			Expect(ended.Status().Description).To(Equal("internal"))
+			Expect(ended.Attributes()).NotTo(ContainElement(HaveField("Key", attribute.Key("error.code"))))
+		})
+	})
+})
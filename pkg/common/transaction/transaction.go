@@ -0,0 +1,27 @@
+// Package transaction lets services group repository calls into a unit of work that is committed or rolled back as a
+// whole, whichever storage engine the repositories use.
+package transaction
+
+import "context"
+
+// Runner runs fn in a transaction. Repository calls made with the ctx passed to fn take part in the transaction, which
+// is committed when fn returns nil and rolled back otherwise. fn is called again when the transaction fails with a
+// transient error, so it must not have effects outside the repositories. Run called within fn joins the surrounding
+// transaction.
+//
+//go:generate mockgen --source=transaction.go --destination=mocks/mock_transaction.go --package=mocks
+type Runner interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type noOp struct{}
+
+// NoOp runs fn once without a transaction, for storage that has none such as the in-memory repositories. Every
+// repository call still applies atomically, but one that fails part way through fn leaves the earlier ones applied.
+func NoOp() Runner {
+	return noOp{}
+}
+
+func (noOp) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,70 @@
+// Package versioning mounts controllers on versioned route groups and advertises the deprecation of old versions.
+package versioning
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// Controller is implemented by every resource controller.
+type Controller interface {
+	RegisterRoutes(router gin.IRouter)
+	openapi.Documenter
+}
+
+// Version is a route prefix such as "/v1" along with its lifecycle. An empty prefix mounts controllers on the
+// engine root.
+type Version struct {
+	Prefix string
+	// DeprecatedAt marks the version as deprecated from the given time, advertised with the Deprecation header.
+	DeprecatedAt time.Time
+	// Sunset is when the version will stop being served, advertised with the Sunset header (RFC 8594).
+	Sunset time.Time
+	// Successor is the prefix clients should migrate to, advertised with a successor-version Link header.
+	Successor string
+	// Legacy keeps the response.Legacy bodies of the unversioned routes, for errors and successes alike.
+	Legacy bool
+	// Middleware runs on the version's routes only.
+	Middleware []gin.HandlerFunc
+}
+
+func (v Version) Deprecated() bool {
+	return !v.DeprecatedAt.IsZero()
+}
+
+// Mount registers the controllers on a route group for the version and documents them in spec. Several versions may
+// be mounted side by side, including different controllers backed by the same service.
+func Mount(engine *gin.Engine, spec *openapi.Generator, version Version, controllers ...Controller) *gin.RouterGroup {
+	group := engine.Group(version.Prefix)
+	if version.Deprecated() {
+		group.Use(Deprecation(version))
+	}
+	if version.Legacy {
+		group.Use(middleware.LegacyErrors())
+	}
+	group.Use(version.Middleware...)
+	for _, c := range controllers {
+		c.RegisterRoutes(group)
+		spec.AddGroup(openapi.Group{Prefix: version.Prefix, Deprecated: version.Deprecated(), Legacy: version.Legacy}, c)
+	}
+	return group
+}
+
+// Deprecation sets the Deprecation, Sunset and Link headers describing the version's lifecycle on every response.
+func Deprecation(version Version) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", fmt.Sprintf("@%d", version.DeprecatedAt.Unix()))
+		if !version.Sunset.IsZero() {
+			ctx.Header("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if version.Successor != "" {
+			ctx.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, version.Successor))
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package versioning_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/versioning"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type greetingService struct{}
+
+func (greetingService) Greet() string {
+	return "hello"
+}
+
+// v1Controller and v2Controller expose the same service with different response shapes.
+type v1Controller struct{ service greetingService }
+
+func (c v1Controller) RegisterRoutes(router gin.IRouter) {
+	router.GET("/greeting", func(ctx *gin.Context) { ctx.String(http.StatusOK, c.service.Greet()) })
+}
+
+func (c v1Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{{Method: http.MethodGet, Path: "/greeting"}}
+}
+
+type v2Controller struct{ service greetingService }
+
+func (c v2Controller) RegisterRoutes(router gin.IRouter) {
+	router.GET("/greeting", func(ctx *gin.Context) { ctx.JSON(http.StatusOK, gin.H{"greeting": c.service.Greet()}) })
+}
+
+func (c v2Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{{Method: http.MethodGet, Path: "/greeting"}}
+}
+
+var _ = Describe("Mount", func() {
+	var (
+		router *gin.Engine
+		spec   *openapi.Generator
+		sunset time.Time
+	)
+
+	BeforeEach(func() {
+		router = gin.New()
+		spec = openapi.NewGenerator(openapi.Info{Title: "test", Version: "1"})
+		sunset = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		service := greetingService{}
+		versioning.Mount(router, spec, versioning.Version{
+			Prefix:       "/v1",
+			DeprecatedAt: time.Unix(100, 0),
+			Sunset:       sunset,
+			Successor:    "/v2",
+		}, v1Controller{service})
+		versioning.Mount(router, spec, versioning.Version{Prefix: "/v2"}, v2Controller{service})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	It("serves both versions side by side", func() {
+		Expect(get("/v1/greeting").Body.String()).To(Equal("hello"))
+		Expect(get("/v2/greeting").Body.String()).To(MatchJSON(`{"greeting":"hello"}`))
+	})
+
+	It("advertises the deprecation of old versions", func() {
+		w := get("/v1/greeting")
+		Expect(w.Header().Get("Deprecation")).To(Equal("@100"))
+		Expect(w.Header().Get("Sunset")).To(Equal("Tue, 01 Jan 2030 00:00:00 GMT"))
+		Expect(w.Header().Get("Link")).To(Equal(`</v2>; rel="successor-version"`))
+	})
+
+	It("does not mark current versions as deprecated", func() {
+		Expect(get("/v2/greeting").Header().Get("Deprecation")).To(BeEmpty())
+	})
+
+	It("documents each version under its prefix", func() {
+		doc, err := spec.Generate(router.Routes())
+		Expect(err).To(BeNil())
+		Expect(doc.Paths["/v1/greeting"]["get"].Deprecated).To(BeTrue())
+		Expect(doc.Paths["/v2/greeting"]["get"].Deprecated).To(BeFalse())
+	})
+})
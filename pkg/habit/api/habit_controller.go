@@ -0,0 +1,491 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/etag"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	habitService "github.com/alexander-littleton/cadence-api/pkg/habit"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CheckInRequest is the optional body of a check-in. Date is the day the habit was done as YYYY-MM-DD, in the
+// client's time zone, and defaults to the current UTC day.
+type CheckInRequest struct {
+	Date string `json:"date"`
+}
+
+type Controller struct {
+	habitService habitService.Service
+}
+
+func New(habitService habitService.Service) Controller {
+	return Controller{
+		habitService: habitService,
+	}
+}
+
+// RegisterRoutes mounts the habit endpoints. Errors are attached to the gin context and rendered by
+// middleware.ErrorHandler, which must be installed on the router.
+func (r Controller) RegisterRoutes(router gin.IRouter) {
+	router.POST("/habit", r.createHabit)
+	router.GET("/habit/:habitId", r.getHabitById)
+	router.PUT("/habit/:habitId", r.updateHabit)
+	router.DELETE("/habit/:habitId", r.deleteHabit)
+	router.GET("/habits", r.getHabitsByUserId)
+	router.GET("/habits/trash", r.getDeletedHabitsByUserId)
+	router.GET("/habits/stats", r.getStats)
+	router.POST("/habit/:habitId/restore", r.restoreHabit)
+	router.POST("/habit/:habitId/archive", r.archiveHabit)
+	router.POST("/habit/:habitId/unarchive", r.unarchiveHabit)
+	router.POST("/habit/:habitId/check-ins", r.recordCheckIn)
+	router.GET("/habit/:habitId/check-ins", r.getCheckIns)
+}
+
+// Operations documents the routes mounted by RegisterRoutes for the OpenAPI spec.
+func (r Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:  http.MethodPost,
+			Path:    "/habit",
+			Summary: "Create a habit",
+			Tags:    []string{"habits"},
+			Request: domain.Habit{},
+			Responses: map[int]openapi.Response{
+				http.StatusCreated: {Description: "The created habit", Body: response.Envelope[domain.Habit]{}},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habit/:habitId",
+			Summary: "Get a habit by id",
+			Tags:    []string{"habits"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "The habit", Body: response.Envelope[domain.Habit]{}},
+			},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/habit/:habitId",
+			Summary: "Replace a habit's name and schedule",
+			Tags:    []string{"habits"},
+			Request: domain.Habit{},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:                 {Description: "The updated habit", Body: response.Envelope[domain.Habit]{}},
+				http.StatusConflict:           {Description: "The habit was updated concurrently"},
+				http.StatusPreconditionFailed: {Description: "The If-Match header does not match the habit's current ETag"},
+			},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/habit/:habitId",
+			Summary: "Delete a habit",
+			Tags:    []string{"habits"},
+			Responses: map[int]openapi.Response{
+				http.StatusNoContent: {Description: "The habit and its check-ins were moved to the trash"},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits",
+			Summary: "List a user's habits",
+			Tags:    []string{"habits"},
+			QueryParams: []openapi.Parameter{
+				{Name: "user_id", Description: "Owner of the habits", Required: true},
+				{Name: "archived", Description: "Exclude (default), include or only archived habits"},
+				{Name: "limit", Description: "Page size, defaults to 20", Type: int(0)},
+				{Name: "offset", Description: "Number of habits to skip", Type: int(0)},
+			},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "A page of habits", Body: response.Envelope[[]domain.Habit]{}},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits/trash",
+			Summary: "List the habits in a user's trash, most recently deleted first",
+			Tags:    []string{"habits"},
+			QueryParams: []openapi.Parameter{
+				{Name: "user_id", Description: "Owner of the habits", Required: true},
+				{Name: "limit", Description: "Page size, defaults to 20", Type: int(0)},
+				{Name: "offset", Description: "Number of habits to skip", Type: int(0)},
+			},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "A page of deleted habits", Body: response.Envelope[[]domain.Habit]{}},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habits/stats",
+			Summary: "Summarise a user's habits and check-ins",
+			Tags:    []string{"habits"},
+			QueryParams: []openapi.Parameter{
+				{Name: "user_id", Description: "Owner of the habits", Required: true},
+				{Name: "archived", Description: "Exclude (default), include or only archived habits"},
+			},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "The user's stats", Body: response.Envelope[domain.Stats]{}},
+			},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/habit/:habitId/restore",
+			Summary: "Restore a habit and its check-ins from the trash",
+			Tags:    []string{"habits"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:       {Description: "The restored habit", Body: response.Envelope[domain.Habit]{}},
+				http.StatusNotFound: {Description: "The habit is not in the trash"},
+			},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/habit/:habitId/archive",
+			Summary: "Archive a habit, keeping its check-ins and streak",
+			Tags:    []string{"habits"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:       {Description: "The archived habit", Body: response.Envelope[domain.Habit]{}},
+				http.StatusNotFound: {Description: "No habit has that id"},
+			},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/habit/:habitId/unarchive",
+			Summary: "Bring an archived habit back",
+			Tags:    []string{"habits"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK:       {Description: "The unarchived habit", Body: response.Envelope[domain.Habit]{}},
+				http.StatusNotFound: {Description: "No habit has that id"},
+			},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/habit/:habitId/check-ins",
+			Summary: "Check a habit in and update its streak",
+			Tags:    []string{"habits"},
+			Request: CheckInRequest{},
+			Responses: map[int]openapi.Response{
+				http.StatusCreated:  {Description: "The check-in, with the habit's new streak", Body: response.Envelope[domain.CheckIn]{}},
+				http.StatusConflict: {Description: "The habit is already checked in on that day"},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/habit/:habitId/check-ins",
+			Summary: "List a habit's check-ins, latest first",
+			Tags:    []string{"habits"},
+			QueryParams: []openapi.Parameter{
+				{Name: "limit", Description: "Page size, defaults to 20", Type: int(0)},
+				{Name: "offset", Description: "Number of check-ins to skip", Type: int(0)},
+			},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {Description: "A page of check-ins", Body: response.Envelope[[]domain.CheckIn]{}},
+			},
+		},
+	}
+}
+
+func (r Controller) createHabit(ctx *gin.Context) {
+	var newHabit domain.Habit
+	if err := ctx.ShouldBindJSON(&newHabit); err != nil {
+		_ = ctx.Error(cadence_errors.FromBinding(err, "failed to unmarshal new habit from request body"))
+		return
+	}
+
+	createdHabit, err := r.habitService.CreateHabit(ctx, newHabit)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, createdHabit.UserId)
+	etag.Set(ctx, createdHabit.Version)
+	ctx.JSON(http.StatusCreated, response.Success(ctx, http.StatusCreated, createdHabit))
+}
+
+func (r Controller) getHabitById(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habit, err := r.habitService.GetHabitById(ctx, habitId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, habit.UserId)
+	etag.Set(ctx, habit.Version)
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, habit))
+}
+
+func (r Controller) updateHabit(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	version, conditional, err := etag.IfMatch(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var habit domain.Habit
+	if err = ctx.ShouldBindJSON(&habit); err != nil {
+		_ = ctx.Error(cadence_errors.FromBinding(err, "failed to unmarshal habit from request body"))
+		return
+	}
+	habit.Id = habitId
+	if conditional {
+		habit.Version = version
+	}
+
+	updatedHabit, err := r.habitService.UpdateHabit(ctx, habit)
+	if err != nil {
+		if conditional {
+			err = etag.Precondition(err)
+		}
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, updatedHabit.UserId)
+	etag.Set(ctx, updatedHabit.Version)
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, updatedHabit))
+}
+
+func (r Controller) deleteHabit(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	if err = r.habitService.DeleteHabit(ctx, habitId); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (r Controller) getHabitsByUserId(ctx *gin.Context) {
+	userId, err := parseObjectId(ctx.Query("user_id"), "user_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	archived, err := parseArchived(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	page, err := parsePage(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habits, total, err := r.habitService.GetHabitsByUserId(ctx, userId, archived, page)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Paginated(ctx, http.StatusOK, habits, page, total))
+}
+
+func (r Controller) getDeletedHabitsByUserId(ctx *gin.Context) {
+	userId, err := parseObjectId(ctx.Query("user_id"), "user_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	page, err := parsePage(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habits, total, err := r.habitService.GetDeletedHabitsByUserId(ctx, userId, page)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Paginated(ctx, http.StatusOK, habits, page, total))
+}
+
+func (r Controller) getStats(ctx *gin.Context) {
+	userId, err := parseObjectId(ctx.Query("user_id"), "user_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	archived, err := parseArchived(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	stats, err := r.habitService.GetStats(ctx, userId, archived)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, stats))
+}
+
+func (r Controller) archiveHabit(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habit, err := r.habitService.ArchiveHabit(ctx, habitId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, habit.UserId)
+	etag.Set(ctx, habit.Version)
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, habit))
+}
+
+func (r Controller) unarchiveHabit(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habit, err := r.habitService.UnarchiveHabit(ctx, habitId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, habit.UserId)
+	etag.Set(ctx, habit.Version)
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, habit))
+}
+
+func (r Controller) restoreHabit(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	habit, err := r.habitService.RestoreHabit(ctx, habitId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, habit.UserId)
+	etag.Set(ctx, habit.Version)
+	ctx.JSON(http.StatusOK, response.Success(ctx, http.StatusOK, habit))
+}
+
+func (r Controller) recordCheckIn(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var request CheckInRequest
+	if err = ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		_ = ctx.Error(cadence_errors.FromBinding(err, "failed to unmarshal check-in from request body"))
+		return
+	}
+	var date time.Time
+	if request.Date != "" {
+		if date, err = time.Parse(time.DateOnly, request.Date); err != nil {
+			_ = ctx.Error(cadence_errors.Wrap(err, cadence_errors.Validation, "check_in.invalid_date", "invalid date").
+				WithField("date", "must be a date formatted as YYYY-MM-DD"))
+			return
+		}
+	}
+
+	checkIn, err := r.habitService.RecordCheckIn(ctx, habitId, date)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	middleware.SetUserId(ctx, checkIn.UserId)
+	ctx.JSON(http.StatusCreated, response.Success(ctx, http.StatusCreated, checkIn))
+}
+
+func (r Controller) getCheckIns(ctx *gin.Context) {
+	habitId, err := parseObjectId(ctx.Param("habitId"), "habit_id")
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	page, err := parsePage(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	checkIns, total, err := r.habitService.GetCheckInsByHabitId(ctx, habitId, page)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Paginated(ctx, http.StatusOK, checkIns, page, total))
+}
+
+func parseObjectId(raw, field string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(raw)
+	if err != nil {
+		return primitive.NilObjectID, cadence_errors.Wrap(err, cadence_errors.Validation, "request.invalid_id", "invalid "+field).
+			WithField(field, "must be a valid object id")
+	}
+	return id, nil
+}
+
+func parsePage(ctx *gin.Context) (pagination.Page, error) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil {
+		return pagination.Page{}, cadence_errors.Wrap(err, cadence_errors.Validation, "pagination.invalid_limit", "invalid limit").
+			WithField("limit", "must be an integer")
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil {
+		return pagination.Page{}, cadence_errors.Wrap(err, cadence_errors.Validation, "pagination.invalid_offset", "invalid offset").
+			WithField("offset", "must be an integer")
+	}
+	return pagination.New(limit, offset)
+}
+
+func parseArchived(ctx *gin.Context) (domain.ArchivedFilter, error) {
+	switch ctx.DefaultQuery("archived", "exclude") {
+	case "exclude":
+		return domain.ExcludeArchived, nil
+	case "include":
+		return domain.IncludeArchived, nil
+	case "only":
+		return domain.OnlyArchived, nil
+	default:
+		return 0, cadence_errors.New(cadence_errors.Validation, "habit.invalid_archived_filter", "invalid archived filter").
+			WithField("archived", "must be one of exclude, include or only")
+	}
+}
@@ -0,0 +1,368 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/api"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ = Describe("Main", func() {
+	var (
+		w            *httptest.ResponseRecorder
+		router       *gin.Engine
+		ctrl         *gomock.Controller
+		habitService *mocks.MockService
+		target       api.Controller
+	)
+
+	BeforeEach(func() {
+		w = httptest.NewRecorder()
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+		ctrl = gomock.NewController(GinkgoT())
+		habitService = mocks.NewMockService(ctrl)
+		target = api.New(habitService)
+		target.RegisterRoutes(router)
+	})
+
+	It("documents exactly the routes it registers", func() {
+		spec := openapi.NewGenerator(openapi.Info{Title: "test", Version: "1"})
+		spec.Add(target)
+		_, err := spec.Generate(router.Routes())
+		Expect(err).To(BeNil())
+	})
+
+	Context("create new habit", func() {
+		var newHabit domain.Habit
+		JustBeforeEach(func() {
+			data, _ := json.Marshal(newHabit)
+			request, _ := http.NewRequest("POST", "/habit", bytes.NewReader(data))
+			router.ServeHTTP(w, request)
+		})
+		Context("the request is valid", func() {
+			BeforeEach(func() {
+				newHabit = domain.Habit{Name: "read", UserId: primitive.NewObjectID()}
+				created := newHabit
+				created.Id = primitive.NewObjectID()
+				habitService.EXPECT().CreateHabit(gomock.Any(), newHabit).Return(created, nil)
+			})
+			It("returns a 201 with the habit as data", func() {
+				Expect(w.Code).To(Equal(201))
+
+				var body response.Envelope[domain.Habit]
+				Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+				Expect(body.Data.Name).To(Equal("read"))
+			})
+		})
+		Context("the habit fails validation", func() {
+			BeforeEach(func() {
+				newHabit = domain.Habit{UserId: primitive.NewObjectID()}
+				habitService.EXPECT().CreateHabit(gomock.Any(), newHabit).Return(domain.Habit{}, cadence_errors.ValidationErr)
+			})
+			It("returns a 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+	})
+
+	Context("list habits", func() {
+		var query string
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("GET", "/habits"+query, nil)
+			router.ServeHTTP(w, request)
+		})
+		Context("the request is valid", func() {
+			BeforeEach(func() {
+				userId := primitive.NewObjectID()
+				query = "?user_id=" + userId.Hex() + "&limit=1&offset=1"
+				habitService.EXPECT().GetHabitsByUserId(gomock.Any(), userId, domain.ExcludeArchived, pagination.Page{Limit: 1, Offset: 1}).
+					Return([]domain.Habit{{Id: primitive.NewObjectID(), UserId: userId}}, int64(3), nil)
+			})
+			It("returns the page with pagination metadata", func() {
+				Expect(w.Code).To(Equal(200))
+
+				var body response.Envelope[[]domain.Habit]
+				Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+				Expect(body.Data).To(HaveLen(1))
+				Expect(*body.Meta.Pagination).To(Equal(response.Pagination{Limit: 1, Offset: 1, Total: 3}))
+			})
+		})
+		Context("archived habits are asked for", func() {
+			BeforeEach(func() {
+				userId := primitive.NewObjectID()
+				query = "?user_id=" + userId.Hex() + "&archived=only"
+				habitService.EXPECT().GetHabitsByUserId(gomock.Any(), userId, domain.OnlyArchived, pagination.Page{Limit: 20}).
+					Return(nil, int64(0), nil)
+			})
+			It("lists only archived habits", func() {
+				Expect(w.Code).To(Equal(200))
+			})
+		})
+		Context("the archived filter is unknown", func() {
+			BeforeEach(func() {
+				query = "?user_id=" + primitive.NewObjectID().Hex() + "&archived=yes"
+			})
+			It("returns a 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+		Context("the user id is malformed", func() {
+			BeforeEach(func() {
+				query = "?user_id=nope"
+			})
+			It("returns a 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+	})
+
+	Context("habit stats", func() {
+		It("returns the stats of the selected habits", func() {
+			userId := primitive.NewObjectID()
+			habitService.EXPECT().GetStats(gomock.Any(), userId, domain.IncludeArchived).
+				Return(domain.Stats{Habits: 2, Archived: 1, CheckIns: 9, LongestStreak: 7}, nil)
+
+			request, _ := http.NewRequest("GET", "/habits/stats?user_id="+userId.Hex()+"&archived=include", nil)
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(200))
+			var body response.Envelope[domain.Stats]
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Data).To(Equal(domain.Stats{Habits: 2, Archived: 1, CheckIns: 9, LongestStreak: 7}))
+		})
+	})
+
+	Context("delete habit", func() {
+		var habitId primitive.ObjectID
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("DELETE", "/habit/"+habitId.Hex(), nil)
+			router.ServeHTTP(w, request)
+		})
+		Context("the habit does not exist", func() {
+			BeforeEach(func() {
+				habitId = primitive.NewObjectID()
+				habitService.EXPECT().DeleteHabit(gomock.Any(), habitId).Return(cadence_errors.ErrNotFound)
+			})
+			It("returns a 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
+		Context("the habit exists", func() {
+			BeforeEach(func() {
+				habitId = primitive.NewObjectID()
+				habitService.EXPECT().DeleteHabit(gomock.Any(), habitId).Return(nil)
+			})
+			It("returns a 204", func() {
+				Expect(w.Code).To(Equal(204))
+			})
+		})
+	})
+
+	Context("list the trash", func() {
+		It("returns the page with pagination metadata", func() {
+			userId := primitive.NewObjectID()
+			deletedAt := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+			habitService.EXPECT().GetDeletedHabitsByUserId(gomock.Any(), userId, pagination.Page{Limit: 20}).
+				Return([]domain.Habit{{Id: primitive.NewObjectID(), UserId: userId, DeletedAt: &deletedAt}}, int64(1), nil)
+
+			request, _ := http.NewRequest("GET", "/habits/trash?user_id="+userId.Hex(), nil)
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(200))
+			var body response.Envelope[[]domain.Habit]
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Data).To(HaveLen(1))
+			Expect(*body.Data[0].DeletedAt).To(BeTemporally("==", deletedAt))
+			Expect(body.Meta.Pagination.Total).To(Equal(int64(1)))
+		})
+	})
+
+	Context("restore habit", func() {
+		var habitId primitive.ObjectID
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("POST", "/habit/"+habitId.Hex()+"/restore", nil)
+			router.ServeHTTP(w, request)
+		})
+		Context("the habit is in the trash", func() {
+			BeforeEach(func() {
+				habitService.EXPECT().RestoreHabit(gomock.Any(), habitId).Return(domain.Habit{Id: habitId, Version: 4}, nil)
+			})
+			It("returns the restored habit with its ETag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(w.Header().Get("ETag")).To(Equal(`"4"`))
+			})
+		})
+		Context("the habit is not in the trash", func() {
+			BeforeEach(func() {
+				habitService.EXPECT().RestoreHabit(gomock.Any(), habitId).Return(domain.Habit{}, cadence_errors.ErrNotFound)
+			})
+			It("returns a 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
+	})
+
+	Context("archive habit", func() {
+		It("returns the archived habit with its ETag", func() {
+			habitId := primitive.NewObjectID()
+			archivedAt := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+			habitService.EXPECT().ArchiveHabit(gomock.Any(), habitId).
+				Return(domain.Habit{Id: habitId, Version: 5, ArchivedAt: &archivedAt}, nil)
+
+			request, _ := http.NewRequest("POST", "/habit/"+habitId.Hex()+"/archive", nil)
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(200))
+			Expect(w.Header().Get("ETag")).To(Equal(`"5"`))
+			var body response.Envelope[domain.Habit]
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(*body.Data.ArchivedAt).To(BeTemporally("==", archivedAt))
+		})
+	})
+
+	Context("unarchive habit", func() {
+		It("returns a 404 when the habit does not exist", func() {
+			habitId := primitive.NewObjectID()
+			habitService.EXPECT().UnarchiveHabit(gomock.Any(), habitId).Return(domain.Habit{}, cadence_errors.ErrNotFound)
+
+			request, _ := http.NewRequest("POST", "/habit/"+habitId.Hex()+"/unarchive", nil)
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(404))
+		})
+	})
+
+	Context("update habit", func() {
+		var (
+			habitId primitive.ObjectID
+			habit   domain.Habit
+			ifMatch string
+		)
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+			habit = domain.Habit{Name: "read", Cadence: domain.Day, RepeatingDays: []uint16{1}}
+			ifMatch = ""
+		})
+		JustBeforeEach(func() {
+			data, _ := json.Marshal(habit)
+			request, _ := http.NewRequest("PUT", "/habit/"+habitId.Hex(), bytes.NewReader(data))
+			if ifMatch != "" {
+				request.Header.Set("If-Match", ifMatch)
+			}
+			router.ServeHTTP(w, request)
+		})
+		Context("If-Match names the current version", func() {
+			BeforeEach(func() {
+				ifMatch = `"3"`
+				expected := habit
+				expected.Id, expected.Version = habitId, 3
+				updated := expected
+				updated.Version = 4
+				habitService.EXPECT().UpdateHabit(gomock.Any(), expected).Return(updated, nil)
+			})
+			It("returns the habit with its new ETag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(w.Header().Get("ETag")).To(Equal(`"4"`))
+			})
+		})
+		Context("If-Match names an older version", func() {
+			BeforeEach(func() {
+				ifMatch = `"2"`
+				habitService.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).Return(domain.Habit{}, cadence_errors.ErrStaleVersion)
+			})
+			It("returns a 412", func() {
+				Expect(w.Code).To(Equal(412))
+			})
+		})
+		Context("If-Match is not an ETag this API issued", func() {
+			BeforeEach(func() {
+				ifMatch = `W/"3"`
+			})
+			It("returns a 412 without updating", func() {
+				Expect(w.Code).To(Equal(412))
+			})
+		})
+		Context("another update lands first without If-Match", func() {
+			BeforeEach(func() {
+				habitService.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).Return(domain.Habit{}, cadence_errors.ErrStaleVersion)
+			})
+			It("returns a 409", func() {
+				Expect(w.Code).To(Equal(409))
+			})
+		})
+	})
+
+	Context("record check-in", func() {
+		var (
+			habitId primitive.ObjectID
+			body    string
+		)
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+			body = ""
+		})
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("POST", "/habit/"+habitId.Hex()+"/check-ins", bytes.NewBufferString(body))
+			router.ServeHTTP(w, request)
+		})
+		Context("the request names a day", func() {
+			BeforeEach(func() {
+				body = `{"date": "2024-03-06"}`
+				date := time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC)
+				habitService.EXPECT().RecordCheckIn(gomock.Any(), habitId, date).
+					Return(domain.CheckIn{HabitId: habitId, Date: date, Streak: 5}, nil)
+			})
+			It("returns a 201 with the check-in as data", func() {
+				Expect(w.Code).To(Equal(201))
+
+				var body response.Envelope[domain.CheckIn]
+				Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+				Expect(body.Data.Streak).To(Equal(uint32(5)))
+			})
+		})
+		Context("the request has no body", func() {
+			BeforeEach(func() {
+				habitService.EXPECT().RecordCheckIn(gomock.Any(), habitId, time.Time{}).Return(domain.CheckIn{}, nil)
+			})
+			It("checks in today", func() {
+				Expect(w.Code).To(Equal(201))
+			})
+		})
+		Context("the date is malformed", func() {
+			BeforeEach(func() {
+				body = `{"date": "06/03/2024"}`
+			})
+			It("returns a 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+		Context("the habit is already checked in that day", func() {
+			BeforeEach(func() {
+				habitService.EXPECT().RecordCheckIn(gomock.Any(), habitId, gomock.Any()).
+					Return(domain.CheckIn{}, cadence_errors.New(cadence_errors.Conflict, "check_in.duplicate", "already checked in"))
+			})
+			It("returns a 409", func() {
+				Expect(w.Code).To(Equal(409))
+			})
+		})
+	})
+})
@@ -1,12 +1,50 @@
 package domain
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type Habit struct {
-	Id            string   `json:"id"`
-	Name          string   `json:"name"`
-	UserId        string   `json:"user_id"`
-	Cadence       Cadence  `json:"cadence"`
-	RepeatingDays []uint16 `json:"repeating_days"`
-	Streak        uint32   `json:"streak"`
+	Id            primitive.ObjectID `json:"id" bson:"_id"`
+	Name          string             `json:"name" bson:"name" validate:"required"`
+	UserId        primitive.ObjectID `json:"user_id" bson:"user_id" validate:"required"`
+	Cadence       Cadence            `json:"cadence" bson:"cadence"`
+	RepeatingDays []uint16           `json:"repeating_days" bson:"repeating_days"`
+	Streak        uint32             `json:"streak" bson:"streak"`
+	// BestStreak is the longest streak the habit has reached and CheckIns the number of check-ins it has. Both are
+	// kept up to date by recording check-ins, so that stats need not read every check-in.
+	BestStreak uint32 `json:"best_streak" bson:"best_streak"`
+	CheckIns   int64  `json:"check_ins" bson:"check_ins"`
+	// Version is incremented by every update and guards against concurrent updates overwriting each other.
+	Version int64 `json:"version" bson:"version"`
+	// ArchivedAt is set while the habit is retired: it keeps its check-ins and streak but takes no new check-ins and
+	// is left out of listings and stats that do not ask for archived habits.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+	// DeletedAt is set while the habit is in the trash, from which it can be restored until it is purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// ArchivedFilter selects habits by whether they are archived.
+type ArchivedFilter uint8
+
+const (
+	ExcludeArchived ArchivedFilter = iota
+	IncludeArchived
+	OnlyArchived
+)
+
+// Matches reports whether the filter selects habit.
+func (f ArchivedFilter) Matches(habit Habit) bool {
+	switch f {
+	case IncludeArchived:
+		return true
+	case OnlyArchived:
+		return habit.ArchivedAt != nil
+	default:
+		return habit.ArchivedAt == nil
+	}
 }
 
 type Cadence uint8
@@ -15,3 +53,27 @@ const (
 	Day Cadence = iota
 	Month
 )
+
+// CheckIn records that a habit was done on a day.
+type CheckIn struct {
+	Id      primitive.ObjectID `json:"id" bson:"_id"`
+	HabitId primitive.ObjectID `json:"habit_id" bson:"habit_id"`
+	UserId  primitive.ObjectID `json:"user_id" bson:"user_id"`
+	// Date is the day the habit was done, at midnight UTC.
+	Date time.Time `json:"date" bson:"date"`
+	// Streak is the habit's streak including this check-in.
+	Streak uint32 `json:"streak" bson:"streak"`
+	// DeletedAt is set while the check-in's habit is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// Stats summarises a user's habits.
+type Stats struct {
+	// Habits is the number of habits counted, Archived of which are archived.
+	Habits   int64 `json:"habits"`
+	Archived int64 `json:"archived"`
+	// CheckIns is the number of check-ins of the habits counted.
+	CheckIns int64 `json:"check_ins"`
+	// LongestStreak is the longest streak any of the habits counted has reached, whether or not it is still running.
+	LongestStreak uint32 `json:"longest_streak"`
+}
@@ -0,0 +1,545 @@
+package habit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/metrics"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/tracing"
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"github.com/alexander-littleton/cadence-api/pkg/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//go:generate mockgen --source=habit_service.go --destination=mocks/mock_habit_service.go --package=mocks
+type Service interface {
+	CreateHabit(ctx context.Context, habit domain.Habit) (domain.Habit, error)
+	GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error)
+	GetHabitsByUserId(
+		ctx context.Context,
+		userId primitive.ObjectID,
+		archived domain.ArchivedFilter,
+		page pagination.Page,
+	) ([]domain.Habit, int64, error)
+	UpdateHabit(ctx context.Context, habit domain.Habit) (domain.Habit, error)
+	ArchiveHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error)
+	UnarchiveHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error)
+	GetStats(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter) (domain.Stats, error)
+	DeleteHabit(ctx context.Context, habitId primitive.ObjectID) error
+	GetDeletedHabitsByUserId(ctx context.Context, userId primitive.ObjectID, page pagination.Page) ([]domain.Habit, int64, error)
+	RestoreHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error)
+	PurgeDeletedHabits(ctx context.Context, deletedBefore time.Time) (habits, checkIns int64, err error)
+	RecordCheckIn(ctx context.Context, habitId primitive.ObjectID, date time.Time) (domain.CheckIn, error)
+	GetCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, page pagination.Page) ([]domain.CheckIn, int64, error)
+}
+
+type service struct {
+	habitRepository   repositories.HabitRepository
+	checkInRepository repositories.CheckInRepository
+	userService       user.Service
+	transactions      transaction.Runner
+	logger            *slog.Logger
+	metrics           *metrics.Metrics
+}
+
+func New(
+	habitRepo repositories.HabitRepository,
+	checkInRepo repositories.CheckInRepository,
+	userService user.Service,
+	transactions transaction.Runner,
+	logger *slog.Logger,
+	metrics *metrics.Metrics,
+) Service {
+	return &service{
+		habitRepository:   habitRepo,
+		checkInRepository: checkInRepo,
+		userService:       userService,
+		transactions:      transactions,
+		logger:            logger,
+		metrics:           metrics,
+	}
+}
+
+func (r *service) CreateHabit(ctx context.Context, habit domain.Habit) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/CreateHabit")
+	defer func() { tracing.End(span, err) }()
+
+	validatedHabit, err := r.validateNewHabit(ctx, habit)
+	if err != nil {
+		return domain.Habit{}, err
+	}
+
+	validatedHabit.Id = primitive.NewObjectID()
+	validatedHabit.Version = 1
+	validatedHabit.DeletedAt = nil
+	validatedHabit.ArchivedAt = nil
+
+	err = r.habitRepository.CreateHabit(ctx, validatedHabit)
+	if err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to create habit: %w", err)
+	}
+
+	r.metrics.HabitCreated()
+	r.logger.InfoContext(ctx, "habit created",
+		slog.String("habit_id", validatedHabit.Id.Hex()),
+		slog.String("owner_id", validatedHabit.UserId.Hex()),
+	)
+	return validatedHabit, nil
+}
+
+// validateNewHabit checks the habit's fields and that its owner exists. For the Day cadence repeating days are days
+// of the week (0 is Sunday) and for the Month cadence they are days of the month.
+func (r *service) validateNewHabit(ctx context.Context, habit domain.Habit) (domain.Habit, error) {
+	if !habit.Id.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.id_not_allowed", "expected a habit without an id")
+	}
+	if habit.Streak != 0 || habit.BestStreak != 0 {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.streak_not_allowed", "a new habit cannot have a streak").
+			WithField("streak", "must be zero").
+			WithField("best_streak", "must be zero")
+	}
+	if habit.CheckIns != 0 {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.check_ins_not_allowed", "a new habit cannot have check-ins").
+			WithField("check_ins", "must be zero")
+	}
+
+	habit.Name = strings.TrimSpace(habit.Name)
+	if habit.Name == "" {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.name_required", "habit name must be provided").
+			WithField("name", "must not be empty")
+	}
+
+	if err := validateSchedule(habit.Cadence, habit.RepeatingDays); err != nil {
+		return domain.Habit{}, err
+	}
+
+	if habit.UserId.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.user_id_required", "valid user id must be provided").
+			WithField("user_id", "must be provided")
+	}
+	_, err := r.userService.GetUserById(ctx, habit.UserId)
+	if cadence_errors.KindOf(err) == cadence_errors.NotFound {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.user_not_found", "habit owner does not exist").
+			WithField("user_id", "must reference an existing user")
+	} else if err != nil {
+		return domain.Habit{}, fmt.Errorf("%s: %w", "failed to get habit owner", err)
+	}
+
+	return habit, nil
+}
+
+func validateSchedule(cadence domain.Cadence, repeatingDays []uint16) error {
+	var maxDay uint16
+	var minDay uint16
+	switch cadence {
+	case domain.Day:
+		minDay, maxDay = 0, 6
+	case domain.Month:
+		minDay, maxDay = 1, 31
+	default:
+		return cadence_errors.New(cadence_errors.Validation, "habit.cadence_invalid", "unknown cadence").
+			WithField("cadence", "must be 0 (day) or 1 (month)")
+	}
+
+	seen := make(map[uint16]bool, len(repeatingDays))
+	for _, day := range repeatingDays {
+		if day < minDay || day > maxDay || seen[day] {
+			return cadence_errors.New(cadence_errors.Validation, "habit.repeating_days_invalid", "invalid repeating days").
+				WithField("repeating_days", fmt.Sprintf("must be unique values between %d and %d", minDay, maxDay))
+		}
+		seen[day] = true
+	}
+	return nil
+}
+
+func (r *service) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/GetHabitById")
+	defer func() { tracing.End(span, err) }()
+
+	if habitId.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	habit, err := r.habitRepository.GetHabitById(ctx, habitId)
+	if err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to get habit with id %s: %w", habitId.Hex(), err)
+	}
+	return habit, nil
+}
+
+// GetHabitsByUserId returns a page of the user's habits selected by archived and the total number of habits selected.
+// A user without habits yields an empty page rather than an error.
+func (r *service) GetHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+	page pagination.Page,
+) (_ []domain.Habit, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/GetHabitsByUserId")
+	defer func() { tracing.End(span, err) }()
+
+	if userId.IsZero() {
+		return nil, 0, cadence_errors.New(cadence_errors.Validation, "habit.user_id_required", "valid user id must be provided")
+	}
+	habits, total, err := r.habitRepository.GetHabitsByUserId(ctx, userId, archived, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get habits for user %s: %w", userId.Hex(), err)
+	}
+	return habits, total, nil
+}
+
+// UpdateHabit replaces the habit's name and schedule; its owner, streaks and check-in count are not changed.
+// A non-zero habit.Version must match the stored version, otherwise the update is based on the version read here.
+// Either way, an update that lands in between makes this one fail with cadence_errors.ErrStaleVersion rather than
+// overwrite it.
+func (r *service) UpdateHabit(ctx context.Context, habit domain.Habit) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/UpdateHabit")
+	defer func() { tracing.End(span, err) }()
+
+	if habit.Id.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	habit.Name = strings.TrimSpace(habit.Name)
+	if habit.Name == "" {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.name_required", "habit name must be provided").
+			WithField("name", "must not be empty")
+	}
+	if err = validateSchedule(habit.Cadence, habit.RepeatingDays); err != nil {
+		return domain.Habit{}, err
+	}
+
+	current, err := r.habitRepository.GetHabitById(ctx, habit.Id)
+	if err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to get habit with id %s: %w", habit.Id.Hex(), err)
+	}
+	if habit.Version != 0 && habit.Version != current.Version {
+		return domain.Habit{}, cadence_errors.ErrStaleVersion.WithDetail("version", current.Version)
+	}
+	if !habit.UserId.IsZero() && habit.UserId != current.UserId {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.owner_immutable", "a habit cannot change owner").
+			WithField("user_id", "must match the habit's owner")
+	}
+
+	current.Name = habit.Name
+	current.Cadence = habit.Cadence
+	current.RepeatingDays = habit.RepeatingDays
+	if err = r.habitRepository.UpdateHabit(ctx, current); err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to update habit with id %s: %w", habit.Id.Hex(), err)
+	}
+	current.Version++
+
+	r.logger.InfoContext(ctx, "habit updated", slog.String("habit_id", current.Id.Hex()))
+	return current, nil
+}
+
+// ArchiveHabit retires the habit, keeping its check-ins and streak. Archiving an archived habit changes nothing.
+func (r *service) ArchiveHabit(ctx context.Context, habitId primitive.ObjectID) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/ArchiveHabit")
+	defer func() { tracing.End(span, err) }()
+
+	return r.setArchived(ctx, habitId, true)
+}
+
+// UnarchiveHabit brings an archived habit back. Its streak carries on if it is checked in on its next scheduled day.
+func (r *service) UnarchiveHabit(ctx context.Context, habitId primitive.ObjectID) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/UnarchiveHabit")
+	defer func() { tracing.End(span, err) }()
+
+	return r.setArchived(ctx, habitId, false)
+}
+
+func (r *service) setArchived(ctx context.Context, habitId primitive.ObjectID, archived bool) (domain.Habit, error) {
+	if habitId.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	habit, err := r.habitRepository.GetHabitById(ctx, habitId)
+	if err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to get habit with id %s: %w", habitId.Hex(), err)
+	}
+	if (habit.ArchivedAt != nil) == archived {
+		return habit, nil
+	}
+
+	habit.ArchivedAt = nil
+	if archived {
+		archivedAt := time.Now().UTC()
+		habit.ArchivedAt = &archivedAt
+	}
+	if err = r.habitRepository.UpdateHabit(ctx, habit); err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to update habit with id %s: %w", habitId.Hex(), err)
+	}
+	habit.Version++
+
+	r.logger.InfoContext(ctx, "habit archive state changed",
+		slog.String("habit_id", habitId.Hex()),
+		slog.Bool("archived", archived),
+	)
+	return habit, nil
+}
+
+// GetStats summarises the user's habits selected by archived.
+func (r *service) GetStats(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+) (_ domain.Stats, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/GetStats")
+	defer func() { tracing.End(span, err) }()
+
+	if userId.IsZero() {
+		return domain.Stats{}, cadence_errors.New(cadence_errors.Validation, "habit.user_id_required", "valid user id must be provided")
+	}
+
+	stats, err := r.habitRepository.GetHabitStats(ctx, userId, archived)
+	if err != nil {
+		return domain.Stats{}, fmt.Errorf("failed to get habit stats for user %s: %w", userId.Hex(), err)
+	}
+	return stats, nil
+}
+
+// DeleteHabit moves the habit together with its check-ins to the trash, from which RestoreHabit brings them back
+// until they are purged.
+func (r *service) DeleteHabit(ctx context.Context, habitId primitive.ObjectID) (err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/DeleteHabit")
+	defer func() { tracing.End(span, err) }()
+
+	if habitId.IsZero() {
+		return cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	deletedAt := time.Now().UTC()
+	err = r.transactions.Run(ctx, func(ctx context.Context) error {
+		if err := r.checkInRepository.DeleteCheckInsByHabitId(ctx, habitId, deletedAt); err != nil {
+			return err
+		}
+		return r.habitRepository.DeleteHabit(ctx, habitId, deletedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete habit with id %s: %w", habitId.Hex(), err)
+	}
+	r.logger.InfoContext(ctx, "habit deleted", slog.String("habit_id", habitId.Hex()))
+	return nil
+}
+
+// GetDeletedHabitsByUserId returns a page of the habits in the user's trash, most recently deleted first, and the
+// total number of habits in it.
+func (r *service) GetDeletedHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) (_ []domain.Habit, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/GetDeletedHabitsByUserId")
+	defer func() { tracing.End(span, err) }()
+
+	if userId.IsZero() {
+		return nil, 0, cadence_errors.New(cadence_errors.Validation, "habit.user_id_required", "valid user id must be provided")
+	}
+	habits, total, err := r.habitRepository.GetDeletedHabitsByUserId(ctx, userId, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get deleted habits for user %s: %w", userId.Hex(), err)
+	}
+	return habits, total, nil
+}
+
+// RestoreHabit takes the habit and its check-ins out of the trash. Habits that are not in the trash are not found.
+func (r *service) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) (_ domain.Habit, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/RestoreHabit")
+	defer func() { tracing.End(span, err) }()
+
+	if habitId.IsZero() {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	var habit domain.Habit
+	err = r.transactions.Run(ctx, func(ctx context.Context) error {
+		if err := r.habitRepository.RestoreHabit(ctx, habitId); err != nil {
+			return err
+		}
+		if err := r.checkInRepository.RestoreCheckInsByHabitId(ctx, habitId); err != nil {
+			return err
+		}
+		var err error
+		habit, err = r.habitRepository.GetHabitById(ctx, habitId)
+		return err
+	})
+	if err != nil {
+		return domain.Habit{}, fmt.Errorf("failed to restore habit with id %s: %w", habitId.Hex(), err)
+	}
+	r.logger.InfoContext(ctx, "habit restored", slog.String("habit_id", habitId.Hex()))
+	return habit, nil
+}
+
+// PurgeDeletedHabits permanently deletes the habits moved to the trash before deletedBefore along with their
+// check-ins, returning how many of each were purged.
+func (r *service) PurgeDeletedHabits(ctx context.Context, deletedBefore time.Time) (habits, checkIns int64, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/PurgeDeletedHabits")
+	defer func() { tracing.End(span, err) }()
+
+	err = r.transactions.Run(ctx, func(ctx context.Context) error {
+		var err error
+		if checkIns, err = r.checkInRepository.PurgeCheckIns(ctx, deletedBefore); err != nil {
+			return err
+		}
+		habits, err = r.habitRepository.PurgeHabits(ctx, deletedBefore)
+		return err
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to purge habits deleted before %s: %w", deletedBefore.Format(time.RFC3339), err)
+	}
+	if habits > 0 || checkIns > 0 {
+		r.logger.InfoContext(ctx, "deleted habits purged",
+			slog.Int64("habits", habits),
+			slog.Int64("check_ins", checkIns),
+		)
+	}
+	return habits, checkIns, nil
+}
+
+// RecordCheckIn records that the habit was done on date, which defaults to today, and extends its streak when the
+// previous check-in was on the habit's previous scheduled day. Otherwise the streak starts again from one. Check-ins
+// are recorded in order: date must be a scheduled day after the latest check-in and no later than tomorrow, leaving
+// room for clients ahead of UTC, and archived habits take no check-ins. The check-in and the habit's new streak and
+// totals are saved in one transaction.
+func (r *service) RecordCheckIn(ctx context.Context, habitId primitive.ObjectID, date time.Time) (_ domain.CheckIn, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/RecordCheckIn")
+	defer func() { tracing.End(span, err) }()
+
+	if habitId.IsZero() {
+		return domain.CheckIn{}, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	today := dayOf(time.Now().UTC())
+	if date.IsZero() {
+		date = today
+	}
+	date = dayOf(date)
+	if date.After(today.AddDate(0, 0, 1)) {
+		return domain.CheckIn{}, cadence_errors.New(cadence_errors.Validation, "check_in.date_in_future", "cannot check in on a future day").
+			WithField("date", "must not be after tomorrow")
+	}
+
+	var (
+		checkIn domain.CheckIn
+		broken  bool
+	)
+	err = r.transactions.Run(ctx, func(ctx context.Context) error {
+		habit, err := r.habitRepository.GetHabitById(ctx, habitId)
+		if err != nil {
+			return fmt.Errorf("failed to get habit with id %s: %w", habitId.Hex(), err)
+		}
+		if habit.ArchivedAt != nil {
+			return cadence_errors.New(cadence_errors.Validation, "check_in.habit_archived", "archived habits cannot be checked in")
+		}
+		if !scheduledOn(habit, date) {
+			return cadence_errors.New(cadence_errors.Validation, "check_in.not_scheduled", "the habit is not scheduled on that day").
+				WithField("date", "must be one of the habit's repeating days")
+		}
+
+		latest, err := r.checkInRepository.GetLatestCheckIn(ctx, habitId)
+		switch {
+		case cadence_errors.KindOf(err) == cadence_errors.NotFound:
+			broken = false
+			habit.Streak = 1
+		case err != nil:
+			return fmt.Errorf("failed to get latest check-in of habit %s: %w", habitId.Hex(), err)
+		case latest.Date.Equal(date):
+			return cadence_errors.New(cadence_errors.Conflict, "check_in.duplicate", "the habit is already checked in on that day")
+		case latest.Date.After(date):
+			return cadence_errors.New(cadence_errors.Validation, "check_in.out_of_order", "the habit has a later check-in").
+				WithField("date", "must be after the latest check-in").
+				WithDetail("latest", latest.Date.Format(time.DateOnly))
+		case latest.Date.Equal(previousScheduledDay(habit, date)):
+			broken = false
+			habit.Streak++
+		default:
+			broken = habit.Streak > 0
+			habit.Streak = 1
+		}
+
+		habit.BestStreak = max(habit.BestStreak, habit.Streak)
+		habit.CheckIns++
+
+		checkIn = domain.CheckIn{
+			Id:      primitive.NewObjectID(),
+			HabitId: habit.Id,
+			UserId:  habit.UserId,
+			Date:    date,
+			Streak:  habit.Streak,
+		}
+		if err = r.checkInRepository.CreateCheckIn(ctx, checkIn); err != nil {
+			return fmt.Errorf("failed to create check-in: %w", err)
+		}
+		if err = r.habitRepository.UpdateHabit(ctx, habit); err != nil {
+			return fmt.Errorf("failed to update streak of habit %s: %w", habitId.Hex(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.CheckIn{}, err
+	}
+
+	r.metrics.CheckInRecorded()
+	if broken {
+		r.metrics.StreakBroken()
+	}
+	r.logger.InfoContext(ctx, "check-in recorded",
+		slog.String("habit_id", habitId.Hex()),
+		slog.String("date", date.Format(time.DateOnly)),
+		slog.Int("streak", int(checkIn.Streak)),
+	)
+	return checkIn, nil
+}
+
+// GetCheckInsByHabitId returns a page of the habit's check-ins, latest first, and the total number it has.
+func (r *service) GetCheckInsByHabitId(
+	ctx context.Context,
+	habitId primitive.ObjectID,
+	page pagination.Page,
+) (_ []domain.CheckIn, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "habit.Service/GetCheckInsByHabitId")
+	defer func() { tracing.End(span, err) }()
+
+	if habitId.IsZero() {
+		return nil, 0, cadence_errors.New(cadence_errors.Validation, "habit.id_required", "valid habit id must be provided")
+	}
+	if _, err = r.habitRepository.GetHabitById(ctx, habitId); err != nil {
+		return nil, 0, fmt.Errorf("failed to get habit with id %s: %w", habitId.Hex(), err)
+	}
+	checkIns, total, err := r.checkInRepository.GetCheckInsByHabitId(ctx, habitId, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get check-ins of habit %s: %w", habitId.Hex(), err)
+	}
+	return checkIns, total, nil
+}
+
+// dayOf returns midnight UTC of the calendar day t falls on in its own location.
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// scheduledOn reports whether the habit is due on day. A habit without repeating days is due every day.
+func scheduledOn(habit domain.Habit, day time.Time) bool {
+	if len(habit.RepeatingDays) == 0 {
+		return true
+	}
+	switch habit.Cadence {
+	case domain.Month:
+		return slices.Contains(habit.RepeatingDays, uint16(day.Day()))
+	default:
+		return slices.Contains(habit.RepeatingDays, uint16(day.Weekday()))
+	}
+}
+
+// previousScheduledDay returns the last day before day on which the habit is due. Every valid schedule has one
+// within two months, as no day of the month is missing from two months in a row.
+func previousScheduledDay(habit domain.Habit, day time.Time) time.Time {
+	previous := day.AddDate(0, 0, -1)
+	for i := 0; i < 62 && !scheduledOn(habit, previous); i++ {
+		previous = previous.AddDate(0, 0, -1)
+	}
+	return previous
+}
@@ -0,0 +1,13 @@
+package habit_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHabitService(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Habit Service Suite")
+}
@@ -0,0 +1,578 @@
+package habit_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
+	"github.com/alexander-littleton/cadence-api/pkg/habit"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	mockRepo "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/mocks"
+	userDomain "github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	mockUser "github.com/alexander-littleton/cadence-api/pkg/user/mocks"
+)
+
+var _ = Describe("Main", func() {
+	var (
+		ctrl        *gomock.Controller
+		habitRepo   *mockRepo.MockHabitRepository
+		checkInRepo *mockRepo.MockCheckInRepository
+		userService *mockUser.MockService
+		target      habit.Service
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		habitRepo = mockRepo.NewMockHabitRepository(ctrl)
+		checkInRepo = mockRepo.NewMockCheckInRepository(ctrl)
+		userService = mockUser.NewMockService(ctrl)
+		target = habit.New(habitRepo, checkInRepo, userService, transaction.NoOp(), logging.Discard(), nil)
+		ctx = context.TODO()
+	})
+
+	Context("CreateHabit", func() {
+		var (
+			newHabit     domain.Habit
+			createdHabit domain.Habit
+			err          error
+		)
+		BeforeEach(func() {
+			newHabit = domain.Habit{
+				Name:          " read ",
+				UserId:        primitive.NewObjectID(),
+				Cadence:       domain.Day,
+				RepeatingDays: []uint16{1, 3, 5},
+			}
+		})
+		JustBeforeEach(func() {
+			createdHabit, err = target.CreateHabit(ctx, newHabit)
+		})
+		Context("the new habit is valid", func() {
+			BeforeEach(func() {
+				userService.EXPECT().GetUserById(gomock.Any(), newHabit.UserId).Return(userDomain.User{Id: newHabit.UserId}, nil)
+				habitRepo.EXPECT().CreateHabit(gomock.Any(), gomock.Any()).Return(nil)
+			})
+			It("returns the created habit at its first version with an id and trimmed name", func() {
+				Expect(err).To(BeNil())
+				Expect(createdHabit.Id.IsZero()).To(BeFalse())
+				Expect(createdHabit.Name).To(Equal("read"))
+				Expect(createdHabit.Version).To(Equal(int64(1)))
+			})
+		})
+		Context("the habit has no name", func() {
+			BeforeEach(func() {
+				newHabit.Name = "  "
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+				Expect(createdHabit).To(Equal(domain.Habit{}))
+			})
+		})
+		Context("the habit claims check-ins", func() {
+			BeforeEach(func() {
+				newHabit.CheckIns = 3
+			})
+			It("returns a validation error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Validation, "habit.check_ins_not_allowed", "")))
+			})
+		})
+		Context("the repeating days do not fit the cadence", func() {
+			BeforeEach(func() {
+				newHabit.RepeatingDays = []uint16{7}
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+		Context("the owner does not exist", func() {
+			BeforeEach(func() {
+				userService.EXPECT().GetUserById(gomock.Any(), newHabit.UserId).Return(userDomain.User{}, cadence_errors.ErrNotFound)
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+		Context("the repository layer returns an error", func() {
+			BeforeEach(func() {
+				userService.EXPECT().GetUserById(gomock.Any(), newHabit.UserId).Return(userDomain.User{Id: newHabit.UserId}, nil)
+				habitRepo.EXPECT().CreateHabit(gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+			})
+			It("returns an error", func() {
+				Expect(err.Error()).To(ContainSubstring("failed to create habit"))
+				Expect(createdHabit).To(Equal(domain.Habit{}))
+			})
+		})
+	})
+	Context("GetHabitsByUserId", func() {
+		var (
+			userId primitive.ObjectID
+			page   pagination.Page
+			habits []domain.Habit
+			total  int64
+			err    error
+		)
+		BeforeEach(func() {
+			userId = primitive.NewObjectID()
+			page = pagination.Page{Limit: 10}
+		})
+		JustBeforeEach(func() {
+			habits, total, err = target.GetHabitsByUserId(ctx, userId, domain.ExcludeArchived, page)
+		})
+		Context("the user has habits", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitsByUserId(gomock.Any(), userId, domain.ExcludeArchived, page).
+					Return([]domain.Habit{{Id: primitive.NewObjectID(), UserId: userId}}, int64(11), nil)
+			})
+			It("returns the page and the total", func() {
+				Expect(err).To(BeNil())
+				Expect(habits).To(HaveLen(1))
+				Expect(total).To(Equal(int64(11)))
+			})
+		})
+		Context("the user id is zero", func() {
+			BeforeEach(func() {
+				userId = primitive.NilObjectID
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+	})
+	Context("UpdateHabit", func() {
+		var (
+			stored  domain.Habit
+			update  domain.Habit
+			updated domain.Habit
+			err     error
+		)
+		BeforeEach(func() {
+			stored = domain.Habit{
+				Id:            primitive.NewObjectID(),
+				Name:          "read",
+				UserId:        primitive.NewObjectID(),
+				Cadence:       domain.Day,
+				RepeatingDays: []uint16{1},
+				Streak:        4,
+				Version:       2,
+			}
+			update = domain.Habit{Id: stored.Id, Name: " write ", Cadence: domain.Month, RepeatingDays: []uint16{15}}
+		})
+		JustBeforeEach(func() {
+			updated, err = target.UpdateHabit(ctx, update)
+		})
+		Context("the update is valid", func() {
+			var saved domain.Habit
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				habitRepo.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, habit domain.Habit) error {
+					saved = habit
+					return nil
+				})
+			})
+			It("saves the new schedule at the version it read and keeps the owner and streak", func() {
+				Expect(err).To(BeNil())
+				Expect(saved.Version).To(Equal(int64(2)))
+				Expect(saved.Name).To(Equal("write"))
+				Expect(saved.UserId).To(Equal(stored.UserId))
+				Expect(saved.Streak).To(Equal(uint32(4)))
+				Expect(updated.Version).To(Equal(int64(3)))
+				Expect(updated.RepeatingDays).To(Equal([]uint16{15}))
+			})
+		})
+		Context("the expected version is not the stored one", func() {
+			BeforeEach(func() {
+				update.Version = 1
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns a stale version error without saving", func() {
+				Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+			})
+		})
+		Context("the update moves the habit to another user", func() {
+			BeforeEach(func() {
+				update.UserId = primitive.NewObjectID()
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+		Context("another update lands first", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				habitRepo.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).Return(cadence_errors.ErrStaleVersion)
+			})
+			It("returns a stale version error", func() {
+				Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+				Expect(updated).To(Equal(domain.Habit{}))
+			})
+		})
+		Context("the repeating days do not fit the cadence", func() {
+			BeforeEach(func() {
+				update.RepeatingDays = []uint16{0}
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+	})
+	Context("DeleteHabit", func() {
+		var (
+			habitId primitive.ObjectID
+			err     error
+		)
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			err = target.DeleteHabit(ctx, habitId)
+		})
+		Context("the habit exists", func() {
+			var checkInsDeletedAt, habitDeletedAt time.Time
+			BeforeEach(func() {
+				gomock.InOrder(
+					checkInRepo.EXPECT().DeleteCheckInsByHabitId(gomock.Any(), habitId, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _ primitive.ObjectID, deletedAt time.Time) error {
+							checkInsDeletedAt = deletedAt
+							return nil
+						}),
+					habitRepo.EXPECT().DeleteHabit(gomock.Any(), habitId, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _ primitive.ObjectID, deletedAt time.Time) error {
+							habitDeletedAt = deletedAt
+							return nil
+						}),
+				)
+			})
+			It("moves the habit and its check-ins to the trash at the same time", func() {
+				Expect(err).To(BeNil())
+				Expect(habitDeletedAt).To(BeTemporally("~", time.Now(), time.Second))
+				Expect(checkInsDeletedAt).To(Equal(habitDeletedAt))
+			})
+		})
+		Context("the habit does not exist", func() {
+			BeforeEach(func() {
+				checkInRepo.EXPECT().DeleteCheckInsByHabitId(gomock.Any(), habitId, gomock.Any()).Return(nil)
+				habitRepo.EXPECT().DeleteHabit(gomock.Any(), habitId, gomock.Any()).Return(cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.NotFound))
+			})
+		})
+	})
+	Context("RestoreHabit", func() {
+		var (
+			habitId  primitive.ObjectID
+			restored domain.Habit
+			err      error
+		)
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			restored, err = target.RestoreHabit(ctx, habitId)
+		})
+		Context("the habit is in the trash", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					habitRepo.EXPECT().RestoreHabit(gomock.Any(), habitId).Return(nil),
+					checkInRepo.EXPECT().RestoreCheckInsByHabitId(gomock.Any(), habitId).Return(nil),
+					habitRepo.EXPECT().GetHabitById(gomock.Any(), habitId).Return(domain.Habit{Id: habitId, Name: "read"}, nil),
+				)
+			})
+			It("restores the habit and its check-ins", func() {
+				Expect(err).To(BeNil())
+				Expect(restored).To(Equal(domain.Habit{Id: habitId, Name: "read"}))
+			})
+		})
+		Context("the habit is not in the trash", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().RestoreHabit(gomock.Any(), habitId).Return(cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.NotFound))
+			})
+		})
+	})
+	Context("PurgeDeletedHabits", func() {
+		cutoff := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		It("purges check-ins and habits deleted before the cutoff", func() {
+			gomock.InOrder(
+				checkInRepo.EXPECT().PurgeCheckIns(gomock.Any(), cutoff).Return(int64(12), nil),
+				habitRepo.EXPECT().PurgeHabits(gomock.Any(), cutoff).Return(int64(2), nil),
+			)
+			habits, checkIns, err := target.PurgeDeletedHabits(ctx, cutoff)
+			Expect(err).To(BeNil())
+			Expect(habits).To(Equal(int64(2)))
+			Expect(checkIns).To(Equal(int64(12)))
+		})
+		It("returns storage errors", func() {
+			checkInRepo.EXPECT().PurgeCheckIns(gomock.Any(), cutoff).Return(int64(0), cadence_errors.ErrInternal)
+			_, _, err := target.PurgeDeletedHabits(ctx, cutoff)
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Internal))
+		})
+	})
+	Context("ArchiveHabit", func() {
+		var (
+			stored   domain.Habit
+			archived domain.Habit
+			err      error
+		)
+		BeforeEach(func() {
+			stored = domain.Habit{Id: primitive.NewObjectID(), Name: "read", Streak: 3, Version: 2}
+		})
+		JustBeforeEach(func() {
+			archived, err = target.ArchiveHabit(ctx, stored.Id)
+		})
+		Context("the habit is active", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				habitRepo.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, habit domain.Habit) error {
+						Expect(habit.ArchivedAt).NotTo(BeNil())
+						Expect(habit.Version).To(Equal(int64(2)))
+						return nil
+					})
+			})
+			It("archives it at its next version and keeps its streak", func() {
+				Expect(err).To(BeNil())
+				Expect(*archived.ArchivedAt).To(BeTemporally("~", time.Now(), time.Minute))
+				Expect(archived.Version).To(Equal(int64(3)))
+				Expect(archived.Streak).To(Equal(uint32(3)))
+			})
+		})
+		Context("the habit is already archived", func() {
+			BeforeEach(func() {
+				archivedAt := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+				stored.ArchivedAt = &archivedAt
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns it unchanged", func() {
+				Expect(err).To(BeNil())
+				Expect(archived).To(Equal(stored))
+			})
+		})
+		Context("the habit does not exist", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(domain.Habit{}, cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.NotFound))
+			})
+		})
+	})
+	Context("UnarchiveHabit", func() {
+		It("clears the archived state at the habit's next version", func() {
+			archivedAt := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+			stored := domain.Habit{Id: primitive.NewObjectID(), Name: "read", Version: 3, ArchivedAt: &archivedAt}
+			habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			expected := stored
+			expected.ArchivedAt = nil
+			habitRepo.EXPECT().UpdateHabit(gomock.Any(), expected).Return(nil)
+
+			unarchived, err := target.UnarchiveHabit(ctx, stored.Id)
+			Expect(err).To(BeNil())
+			Expect(unarchived.ArchivedAt).To(BeNil())
+			Expect(unarchived.Version).To(Equal(int64(4)))
+		})
+	})
+	Context("GetStats", func() {
+		var userId primitive.ObjectID
+		BeforeEach(func() {
+			userId = primitive.NewObjectID()
+		})
+		It("reads the user's stats from the repository", func() {
+			expected := domain.Stats{Habits: 2, Archived: 1, CheckIns: 9, LongestStreak: 7}
+			habitRepo.EXPECT().GetHabitStats(gomock.Any(), userId, domain.IncludeArchived).Return(expected, nil)
+
+			stats, err := target.GetStats(ctx, userId, domain.IncludeArchived)
+			Expect(err).To(BeNil())
+			Expect(stats).To(Equal(expected))
+		})
+		It("rejects a zero user id", func() {
+			_, err := target.GetStats(ctx, primitive.NilObjectID, domain.ExcludeArchived)
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Validation))
+		})
+	})
+	Context("RecordCheckIn", func() {
+		var (
+			stored  domain.Habit
+			date    time.Time
+			checkIn domain.CheckIn
+			err     error
+		)
+		day := func(d int) time.Time {
+			return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+		}
+		expectStreak := func(streak, best uint32) {
+			checkInRepo.EXPECT().CreateCheckIn(gomock.Any(), gomock.Any()).Return(nil)
+			updated := stored
+			updated.Streak = streak
+			updated.BestStreak = best
+			updated.CheckIns = stored.CheckIns + 1
+			habitRepo.EXPECT().UpdateHabit(gomock.Any(), updated).Return(nil)
+		}
+		BeforeEach(func() {
+			// Mondays, Wednesdays and Fridays; March 6th 2024 is a Wednesday
+			stored = domain.Habit{
+				Id:            primitive.NewObjectID(),
+				UserId:        primitive.NewObjectID(),
+				Name:          "read",
+				Cadence:       domain.Day,
+				RepeatingDays: []uint16{1, 3, 5},
+				Streak:        4,
+				BestStreak:    6,
+				CheckIns:      10,
+				Version:       2,
+			}
+			date = time.Date(2024, time.March, 6, 21, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+		})
+		JustBeforeEach(func() {
+			checkIn, err = target.RecordCheckIn(ctx, stored.Id, date)
+		})
+		Context("the previous scheduled day was checked in", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{Date: day(4)}, nil)
+				expectStreak(5, 6)
+			})
+			It("extends the streak", func() {
+				Expect(err).To(BeNil())
+				Expect(checkIn.Id.IsZero()).To(BeFalse())
+				Expect(checkIn).To(Equal(domain.CheckIn{
+					Id:      checkIn.Id,
+					HabitId: stored.Id,
+					UserId:  stored.UserId,
+					Date:    day(6),
+					Streak:  5,
+				}))
+			})
+		})
+		Context("the extended streak is the habit's longest", func() {
+			BeforeEach(func() {
+				stored.BestStreak = 4
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{Date: day(4)}, nil)
+				expectStreak(5, 5)
+			})
+			It("becomes the best streak", func() {
+				Expect(err).To(BeNil())
+				Expect(checkIn.Streak).To(Equal(uint32(5)))
+			})
+		})
+		Context("a scheduled day was missed", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{Date: day(1)}, nil)
+				expectStreak(1, 6)
+			})
+			It("starts the streak again", func() {
+				Expect(err).To(BeNil())
+				Expect(checkIn.Streak).To(Equal(uint32(1)))
+			})
+		})
+		Context("the habit has no check-ins yet", func() {
+			BeforeEach(func() {
+				stored.Streak, stored.BestStreak, stored.CheckIns = 0, 0, 0
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{}, cadence_errors.ErrNotFound)
+				expectStreak(1, 1)
+			})
+			It("starts a streak", func() {
+				Expect(err).To(BeNil())
+				Expect(checkIn.Streak).To(Equal(uint32(1)))
+			})
+		})
+		Context("the habit is already checked in that day", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{Date: day(6)}, nil)
+			})
+			It("returns a conflict error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Conflict, "check_in.duplicate", "")))
+			})
+		})
+		Context("the habit is not scheduled that day", func() {
+			BeforeEach(func() {
+				date = day(5)
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns a validation error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Validation, "check_in.not_scheduled", "")))
+			})
+		})
+		Context("the habit is archived", func() {
+			BeforeEach(func() {
+				archivedAt := day(1)
+				stored.ArchivedAt = &archivedAt
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns a validation error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Validation, "check_in.habit_archived", "")))
+			})
+		})
+		Context("the day is in the future", func() {
+			BeforeEach(func() {
+				date = time.Now().AddDate(0, 0, 3)
+			})
+			It("returns a validation error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Validation, "check_in.date_in_future", "")))
+			})
+		})
+		Context("another update lands on the habit first", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), stored.Id).Return(stored, nil)
+				checkInRepo.EXPECT().GetLatestCheckIn(gomock.Any(), stored.Id).Return(domain.CheckIn{Date: day(4)}, nil)
+				checkInRepo.EXPECT().CreateCheckIn(gomock.Any(), gomock.Any()).Return(nil)
+				habitRepo.EXPECT().UpdateHabit(gomock.Any(), gomock.Any()).Return(cadence_errors.ErrStaleVersion)
+			})
+			It("returns a stale version error", func() {
+				Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+				Expect(checkIn).To(Equal(domain.CheckIn{}))
+			})
+		})
+	})
+	Context("GetCheckInsByHabitId", func() {
+		var (
+			habitId  primitive.ObjectID
+			checkIns []domain.CheckIn
+			total    int64
+			err      error
+		)
+		BeforeEach(func() {
+			habitId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			checkIns, total, err = target.GetCheckInsByHabitId(ctx, habitId, pagination.Page{Limit: 10})
+		})
+		Context("the habit has check-ins", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), habitId).Return(domain.Habit{Id: habitId}, nil)
+				checkInRepo.EXPECT().GetCheckInsByHabitId(gomock.Any(), habitId, pagination.Page{Limit: 10}).
+					Return([]domain.CheckIn{{HabitId: habitId}}, int64(11), nil)
+			})
+			It("returns the page and total", func() {
+				Expect(err).To(BeNil())
+				Expect(checkIns).To(HaveLen(1))
+				Expect(total).To(Equal(int64(11)))
+			})
+		})
+		Context("the habit does not exist", func() {
+			BeforeEach(func() {
+				habitRepo.EXPECT().GetHabitById(gomock.Any(), habitId).Return(domain.Habit{}, cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.NotFound))
+			})
+		})
+	})
+})
@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: habit_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	pagination "github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	domain "github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ArchiveHabit mocks base method.
+func (m *MockService) ArchiveHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveHabit", ctx, habitId)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveHabit indicates an expected call of ArchiveHabit.
+func (mr *MockServiceMockRecorder) ArchiveHabit(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveHabit", reflect.TypeOf((*MockService)(nil).ArchiveHabit), ctx, habitId)
+}
+
+// CreateHabit mocks base method.
+func (m *MockService) CreateHabit(ctx context.Context, habit domain.Habit) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHabit", ctx, habit)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHabit indicates an expected call of CreateHabit.
+func (mr *MockServiceMockRecorder) CreateHabit(ctx, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHabit", reflect.TypeOf((*MockService)(nil).CreateHabit), ctx, habit)
+}
+
+// DeleteHabit mocks base method.
+func (m *MockService) DeleteHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHabit", ctx, habitId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHabit indicates an expected call of DeleteHabit.
+func (mr *MockServiceMockRecorder) DeleteHabit(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHabit", reflect.TypeOf((*MockService)(nil).DeleteHabit), ctx, habitId)
+}
+
+// GetCheckInsByHabitId mocks base method.
+func (m *MockService) GetCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, page pagination.Page) ([]domain.CheckIn, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckInsByHabitId", ctx, habitId, page)
+	ret0, _ := ret[0].([]domain.CheckIn)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCheckInsByHabitId indicates an expected call of GetCheckInsByHabitId.
+func (mr *MockServiceMockRecorder) GetCheckInsByHabitId(ctx, habitId, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckInsByHabitId", reflect.TypeOf((*MockService)(nil).GetCheckInsByHabitId), ctx, habitId, page)
+}
+
+// GetDeletedHabitsByUserId mocks base method.
+func (m *MockService) GetDeletedHabitsByUserId(ctx context.Context, userId primitive.ObjectID, page pagination.Page) ([]domain.Habit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedHabitsByUserId", ctx, userId, page)
+	ret0, _ := ret[0].([]domain.Habit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDeletedHabitsByUserId indicates an expected call of GetDeletedHabitsByUserId.
+func (mr *MockServiceMockRecorder) GetDeletedHabitsByUserId(ctx, userId, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedHabitsByUserId", reflect.TypeOf((*MockService)(nil).GetDeletedHabitsByUserId), ctx, userId, page)
+}
+
+// GetHabitById mocks base method.
+func (m *MockService) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitById", ctx, habitId)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitById indicates an expected call of GetHabitById.
+func (mr *MockServiceMockRecorder) GetHabitById(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitById", reflect.TypeOf((*MockService)(nil).GetHabitById), ctx, habitId)
+}
+
+// GetHabitsByUserId mocks base method.
+func (m *MockService) GetHabitsByUserId(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter, page pagination.Page) ([]domain.Habit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitsByUserId", ctx, userId, archived, page)
+	ret0, _ := ret[0].([]domain.Habit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetHabitsByUserId indicates an expected call of GetHabitsByUserId.
+func (mr *MockServiceMockRecorder) GetHabitsByUserId(ctx, userId, archived, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitsByUserId", reflect.TypeOf((*MockService)(nil).GetHabitsByUserId), ctx, userId, archived, page)
+}
+
+// GetStats mocks base method.
+func (m *MockService) GetStats(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter) (domain.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, userId, archived)
+	ret0, _ := ret[0].(domain.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockServiceMockRecorder) GetStats(ctx, userId, archived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockService)(nil).GetStats), ctx, userId, archived)
+}
+
+// PurgeDeletedHabits mocks base method.
+func (m *MockService) PurgeDeletedHabits(ctx context.Context, deletedBefore time.Time) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedHabits", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PurgeDeletedHabits indicates an expected call of PurgeDeletedHabits.
+func (mr *MockServiceMockRecorder) PurgeDeletedHabits(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedHabits", reflect.TypeOf((*MockService)(nil).PurgeDeletedHabits), ctx, deletedBefore)
+}
+
+// RecordCheckIn mocks base method.
+func (m *MockService) RecordCheckIn(ctx context.Context, habitId primitive.ObjectID, date time.Time) (domain.CheckIn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCheckIn", ctx, habitId, date)
+	ret0, _ := ret[0].(domain.CheckIn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordCheckIn indicates an expected call of RecordCheckIn.
+func (mr *MockServiceMockRecorder) RecordCheckIn(ctx, habitId, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCheckIn", reflect.TypeOf((*MockService)(nil).RecordCheckIn), ctx, habitId, date)
+}
+
+// RestoreHabit mocks base method.
+func (m *MockService) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreHabit", ctx, habitId)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreHabit indicates an expected call of RestoreHabit.
+func (mr *MockServiceMockRecorder) RestoreHabit(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreHabit", reflect.TypeOf((*MockService)(nil).RestoreHabit), ctx, habitId)
+}
+
+// UnarchiveHabit mocks base method.
+func (m *MockService) UnarchiveHabit(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnarchiveHabit", ctx, habitId)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnarchiveHabit indicates an expected call of UnarchiveHabit.
+func (mr *MockServiceMockRecorder) UnarchiveHabit(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnarchiveHabit", reflect.TypeOf((*MockService)(nil).UnarchiveHabit), ctx, habitId)
+}
+
+// UpdateHabit mocks base method.
+func (m *MockService) UpdateHabit(ctx context.Context, habit domain.Habit) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHabit", ctx, habit)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHabit indicates an expected call of UpdateHabit.
+func (mr *MockServiceMockRecorder) UpdateHabit(ctx, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHabit", reflect.TypeOf((*MockService)(nil).UpdateHabit), ctx, habit)
+}
@@ -0,0 +1,28 @@
+package habit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+)
+
+// PurgeTrash purges the habits that have been in the trash for longer than retention, once when it starts and then
+// every interval until ctx is cancelled. A purge that fails is retried on the next tick rather than stopping the
+// application. Each purge gets its own request id, so that the log lines of a run can be told apart.
+func PurgeTrash(ctx context.Context, service Service, retention, interval time.Duration, logger *slog.Logger) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runCtx := reqctx.WithRequestId(ctx, reqctx.NewRequestId())
+		if _, _, err := service.PurgeDeletedHabits(runCtx, time.Now().UTC().Add(-retention)); err != nil && ctx.Err() == nil {
+			logger.ErrorContext(runCtx, "failed to purge deleted habits", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package habit_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/reqctx"
+	"github.com/alexander-littleton/cadence-api/pkg/habit"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/mocks"
+)
+
+var _ = Describe("PurgeTrash", func() {
+	var (
+		service *mocks.MockService
+		ctx     context.Context
+		cancel  context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		service = mocks.NewMockService(gomock.NewController(GinkgoT()))
+		ctx, cancel = context.WithCancel(context.TODO())
+		DeferCleanup(cancel)
+	})
+
+	It("purges habits deleted longer than the retention ago until cancelled", func() {
+		var cutoff time.Time
+		service.EXPECT().PurgeDeletedHabits(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, deletedBefore time.Time) (int64, int64, error) {
+				cutoff = deletedBefore
+				cancel()
+				return 1, 3, nil
+			})
+
+		Expect(habit.PurgeTrash(ctx, service, 24*time.Hour, time.Hour, logging.Discard())).To(Succeed())
+		Expect(cutoff).To(BeTemporally("~", time.Now().Add(-24*time.Hour), time.Second))
+	})
+
+	It("gives every purge its own request id", func() {
+		var ids []string
+		service.EXPECT().PurgeDeletedHabits(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ time.Time) (int64, int64, error) {
+				if ids = append(ids, reqctx.RequestId(ctx)); len(ids) == 2 {
+					cancel()
+				}
+				return 0, 0, nil
+			}).Times(2)
+
+		Expect(habit.PurgeTrash(ctx, service, time.Hour, time.Millisecond, logging.Discard())).To(Succeed())
+		Expect(ids[0]).NotTo(BeEmpty())
+		Expect(ids[1]).NotTo(Equal(ids[0]))
+	})
+
+	It("keeps purging after a failure", func() {
+		gomock.InOrder(
+			service.EXPECT().PurgeDeletedHabits(gomock.Any(), gomock.Any()).Return(int64(0), int64(0), cadence_errors.ErrInternal),
+			service.EXPECT().PurgeDeletedHabits(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(context.Context, time.Time) (int64, int64, error) {
+					cancel()
+					return 0, 0, nil
+				}),
+		)
+
+		Expect(habit.PurgeTrash(ctx, service, time.Hour, time.Millisecond, logging.Discard())).To(Succeed())
+	})
+})
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CheckInRepository persists check-ins. Like HabitRepository, implementations must translate storage specific
+// failures into cadence_errors kinds and leave check-ins in the trash out unless told otherwise.
+//
+//go:generate mockgen --source=check_in_repository.go --destination=mocks/mock_check_in_repository.go --package=mocks
+type CheckInRepository interface {
+	// CreateCheckIn returns a Conflict error when the habit already has a check-in on checkIn.Date.
+	CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error
+	// GetLatestCheckIn returns the habit's check-in with the latest date, or a NotFound error when it has none.
+	GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error)
+	// GetCheckInsByHabitId returns a page of the habit's check-ins, latest first, along with the total number of
+	// check-ins the habit has.
+	GetCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, page pagination.Page) ([]domain.CheckIn, int64, error)
+	// DeleteCheckInsByHabitId moves every check-in of the habit to the trash along with it, recording deletedAt as
+	// their DeletedAt. It succeeds when the habit has none.
+	DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error
+	// RestoreCheckInsByHabitId takes the habit's check-ins out of the trash, succeeding when there are none.
+	RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error
+	// PurgeCheckIns permanently deletes the check-ins moved to the trash before deletedBefore, returning how many
+	// there were.
+	PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// DeleteCheckInsByUserId permanently deletes every check-in of the user's habits, including those in the trash,
+	// succeeding when there are none.
+	DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error
+}
@@ -0,0 +1,137 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CheckInRepository registers the contract specs for the named backend. newRepository is called before every spec and
+// must return an empty repository.
+func CheckInRepository(backend string, newRepository func() repositories.CheckInRepository) bool {
+	return Describe(backend+" CheckInRepository contract", func() {
+		var (
+			target   repositories.CheckInRepository
+			ctx      context.Context
+			owner    primitive.ObjectID
+			habitId  primitive.ObjectID
+			checkIns []domain.CheckIn
+			other    domain.CheckIn
+		)
+
+		deletedAt := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+		day := func(d int) time.Time {
+			return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+		}
+
+		BeforeEach(func() {
+			target = newRepository()
+			ctx = context.TODO()
+			owner = primitive.NewObjectID()
+			habitId = primitive.NewObjectID()
+			// checkIns is latest first, but the check-ins are created out of order
+			checkIns = []domain.CheckIn{
+				{Id: primitive.NewObjectID(), HabitId: habitId, UserId: owner, Date: day(3), Streak: 3},
+				{Id: primitive.NewObjectID(), HabitId: habitId, UserId: owner, Date: day(2), Streak: 2},
+				{Id: primitive.NewObjectID(), HabitId: habitId, UserId: owner, Date: day(1), Streak: 1},
+			}
+			for _, i := range []int{2, 0, 1} {
+				Expect(target.CreateCheckIn(ctx, checkIns[i])).To(Succeed())
+			}
+			other = domain.CheckIn{Id: primitive.NewObjectID(), HabitId: primitive.NewObjectID(), UserId: owner, Date: day(4), Streak: 1}
+			Expect(target.CreateCheckIn(ctx, other)).To(Succeed())
+		})
+
+		It("finds a habit's latest check-in", func() {
+			Expect(target.GetLatestCheckIn(ctx, habitId)).To(Equal(checkIns[0]))
+		})
+
+		It("returns not found for habits without check-ins", func() {
+			_, err := target.GetLatestCheckIn(ctx, primitive.NewObjectID())
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("rejects a second check-in on the same day", func() {
+			again := checkIns[1]
+			again.Id = primitive.NewObjectID()
+			Expect(target.CreateCheckIn(ctx, again)).To(MatchError(cadence_errors.ErrConflict))
+		})
+
+		It("pages through a habit's check-ins latest first with the total count", func() {
+			page, total, err := target.GetCheckInsByHabitId(ctx, habitId, pagination.Page{Limit: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).To(Equal(checkIns[:2]))
+
+			page, total, err = target.GetCheckInsByHabitId(ctx, habitId, pagination.Page{Limit: 2, Offset: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).To(Equal(checkIns[2:]))
+		})
+
+		It("returns an empty page for habits without check-ins", func() {
+			page, total, err := target.GetCheckInsByHabitId(ctx, primitive.NewObjectID(), pagination.Page{Limit: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			Expect(page).NotTo(BeNil())
+			Expect(page).To(BeEmpty())
+		})
+
+		It("moves a habit's check-ins and only theirs to the trash, and restores them", func() {
+			Expect(target.DeleteCheckInsByHabitId(ctx, habitId, deletedAt)).To(Succeed())
+			_, err := target.GetLatestCheckIn(ctx, habitId)
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			_, total, err := target.GetCheckInsByHabitId(ctx, habitId, pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			Expect(target.GetLatestCheckIn(ctx, other.HabitId)).To(Equal(other))
+			Expect(target.DeleteCheckInsByHabitId(ctx, habitId, deletedAt)).To(Succeed())
+
+			Expect(target.RestoreCheckInsByHabitId(ctx, habitId)).To(Succeed())
+			page, total, err := target.GetCheckInsByHabitId(ctx, habitId, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).To(Equal(checkIns))
+			Expect(target.RestoreCheckInsByHabitId(ctx, primitive.NewObjectID())).To(Succeed())
+		})
+
+		It("purges the check-ins deleted before the cutoff", func() {
+			Expect(target.DeleteCheckInsByHabitId(ctx, habitId, deletedAt)).To(Succeed())
+			Expect(target.DeleteCheckInsByHabitId(ctx, other.HabitId, deletedAt.Add(time.Hour))).To(Succeed())
+
+			Expect(target.PurgeCheckIns(ctx, deletedAt.Add(time.Hour))).To(Equal(int64(3)))
+			Expect(target.RestoreCheckInsByHabitId(ctx, habitId)).To(Succeed())
+			Expect(target.RestoreCheckInsByHabitId(ctx, other.HabitId)).To(Succeed())
+			_, err := target.GetLatestCheckIn(ctx, habitId)
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.GetLatestCheckIn(ctx, other.HabitId)).To(Equal(other))
+		})
+
+		It("deletes every check-in of a user, including those in the trash", func() {
+			Expect(target.DeleteCheckInsByHabitId(ctx, habitId, deletedAt)).To(Succeed())
+			Expect(target.DeleteCheckInsByUserId(ctx, owner)).To(Succeed())
+			Expect(target.RestoreCheckInsByHabitId(ctx, habitId)).To(Succeed())
+			for _, id := range []primitive.ObjectID{habitId, other.HabitId} {
+				_, err := target.GetLatestCheckIn(ctx, id)
+				Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			}
+			Expect(target.DeleteCheckInsByUserId(ctx, primitive.NewObjectID())).To(Succeed())
+		})
+
+		It("fails with an internal error once the context is done", func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := target.GetLatestCheckIn(cancelled, habitId)
+			Expect(err).NotTo(BeNil())
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Internal))
+		})
+	})
+}
@@ -0,0 +1,296 @@
+// Package contract is the behaviour every repositories.HabitRepository and repositories.CheckInRepository must share,
+// written as Ginkgo specs that the repositories suite runs against every backend.
+package contract
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HabitRepository registers the contract specs for the named backend. newRepository is called before every spec and
+// must return an empty repository.
+func HabitRepository(backend string, newRepository func() repositories.HabitRepository) bool {
+	return Describe(backend+" HabitRepository contract", func() {
+		var (
+			target repositories.HabitRepository
+			ctx    context.Context
+			owner  primitive.ObjectID
+			habits []domain.Habit
+		)
+
+		deletedAt := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			target = newRepository()
+			ctx = context.TODO()
+			owner = primitive.NewObjectID()
+			habits = nil
+			// ids are generated in increasing order, so habits is sorted by id
+			for _, name := range []string{"read", "run", "write"} {
+				habit := domain.Habit{
+					Id:            primitive.NewObjectID(),
+					Name:          name,
+					UserId:        owner,
+					Cadence:       domain.Day,
+					RepeatingDays: []uint16{1, 3},
+					Version:       1,
+				}
+				Expect(target.CreateHabit(ctx, habit)).To(Succeed())
+				habits = append(habits, habit)
+			}
+			other := domain.Habit{Id: primitive.NewObjectID(), Name: "other", UserId: primitive.NewObjectID(), RepeatingDays: []uint16{}}
+			Expect(target.CreateHabit(ctx, other)).To(Succeed())
+		})
+
+		It("finds habits by id", func() {
+			Expect(target.GetHabitById(ctx, habits[1].Id)).To(Equal(habits[1]))
+		})
+
+		It("returns not found for unknown habits", func() {
+			_, err := target.GetHabitById(ctx, primitive.NewObjectID())
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("rejects a duplicate id", func() {
+			Expect(target.CreateHabit(ctx, habits[0])).To(MatchError(cadence_errors.ErrConflict))
+		})
+
+		It("pages through a user's habits in id order with the total count", func() {
+			page, total, err := target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).To(Equal(habits[:2]))
+
+			page, total, err = target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 2, Offset: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).To(Equal(habits[2:]))
+		})
+
+		It("returns an empty page past the end or for users without habits", func() {
+			page, total, err := target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 2, Offset: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+			Expect(page).NotTo(BeNil())
+			Expect(page).To(BeEmpty())
+
+			page, total, err = target.GetHabitsByUserId(ctx, primitive.NewObjectID(), domain.ExcludeArchived, pagination.Page{Limit: 2})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			Expect(page).To(BeEmpty())
+		})
+
+		It("filters a user's habits by whether they are archived", func() {
+			archived := habits[1]
+			archivedAt := deletedAt
+			archived.ArchivedAt = &archivedAt
+			Expect(target.UpdateHabit(ctx, archived)).To(Succeed())
+			archived.Version++
+
+			page, total, err := target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(2)))
+			Expect(page).To(Equal([]domain.Habit{habits[0], habits[2]}))
+
+			page, total, err = target.GetHabitsByUserId(ctx, owner, domain.OnlyArchived, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(1)))
+			Expect(page).To(HaveLen(1))
+			Expect(page[0].Id).To(Equal(archived.Id))
+			Expect(*page[0].ArchivedAt).To(BeTemporally("==", archivedAt))
+
+			_, total, err = target.GetHabitsByUserId(ctx, owner, domain.IncludeArchived, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(3)))
+
+			archived.ArchivedAt = nil
+			Expect(target.UpdateHabit(ctx, archived)).To(Succeed())
+			_, total, err = target.GetHabitsByUserId(ctx, owner, domain.OnlyArchived, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+		})
+
+		It("summarises a user's habits selected by archived from their totals", func() {
+			running := habits[0]
+			running.Streak, running.BestStreak, running.CheckIns = 2, 5, 7
+			Expect(target.UpdateHabit(ctx, running)).To(Succeed())
+			archived := habits[1]
+			archivedAt := deletedAt
+			archived.ArchivedAt = &archivedAt
+			archived.BestStreak, archived.CheckIns = 8, 3
+			Expect(target.UpdateHabit(ctx, archived)).To(Succeed())
+			deleted := habits[2]
+			deleted.BestStreak, deleted.CheckIns = 50, 100
+			Expect(target.UpdateHabit(ctx, deleted)).To(Succeed())
+			Expect(target.DeleteHabit(ctx, deleted.Id, deletedAt)).To(Succeed())
+
+			Expect(target.GetHabitStats(ctx, owner, domain.ExcludeArchived)).
+				To(Equal(domain.Stats{Habits: 1, CheckIns: 7, LongestStreak: 5}))
+			Expect(target.GetHabitStats(ctx, owner, domain.OnlyArchived)).
+				To(Equal(domain.Stats{Habits: 1, Archived: 1, CheckIns: 3, LongestStreak: 8}))
+			Expect(target.GetHabitStats(ctx, owner, domain.IncludeArchived)).
+				To(Equal(domain.Stats{Habits: 2, Archived: 1, CheckIns: 10, LongestStreak: 8}))
+			Expect(target.GetHabitStats(ctx, primitive.NewObjectID(), domain.IncludeArchived)).To(Equal(domain.Stats{}))
+		})
+
+		It("moves deleted habits to the trash, out of reach of every other method", func() {
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(Succeed())
+			_, err := target.GetHabitById(ctx, habits[0].Id)
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			page, total, err := target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 5})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(2)))
+			Expect(page).To(Equal(habits[1:]))
+			Expect(target.UpdateHabit(ctx, habits[0])).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.DeleteHabit(ctx, primitive.NewObjectID(), deletedAt)).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("pages through a user's trash most recently deleted first", func() {
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(Succeed())
+			Expect(target.DeleteHabit(ctx, habits[2].Id, deletedAt.Add(time.Hour))).To(Succeed())
+
+			page, total, err := target.GetDeletedHabitsByUserId(ctx, owner, pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(2)))
+			Expect(page).To(HaveLen(1))
+			Expect(page[0].Id).To(Equal(habits[2].Id))
+			Expect(page[0].DeletedAt).NotTo(BeNil())
+			Expect(*page[0].DeletedAt).To(BeTemporally("==", deletedAt.Add(time.Hour)))
+
+			page, _, err = target.GetDeletedHabitsByUserId(ctx, owner, pagination.Page{Limit: 1, Offset: 1})
+			Expect(err).To(BeNil())
+			Expect(page).To(HaveLen(1))
+			Expect(page[0].Id).To(Equal(habits[0].Id))
+
+			page, total, err = target.GetDeletedHabitsByUserId(ctx, primitive.NewObjectID(), pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			Expect(page).NotTo(BeNil())
+			Expect(page).To(BeEmpty())
+		})
+
+		It("restores habits from the trash and reports habits outside it as not found", func() {
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(Succeed())
+			Expect(target.RestoreHabit(ctx, habits[0].Id)).To(Succeed())
+			Expect(target.GetHabitById(ctx, habits[0].Id)).To(Equal(habits[0]))
+			Expect(target.RestoreHabit(ctx, habits[0].Id)).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.RestoreHabit(ctx, primitive.NewObjectID())).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("purges the habits deleted before the cutoff", func() {
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(Succeed())
+			Expect(target.DeleteHabit(ctx, habits[2].Id, deletedAt.Add(time.Hour))).To(Succeed())
+
+			Expect(target.PurgeHabits(ctx, deletedAt.Add(time.Hour))).To(Equal(int64(1)))
+			Expect(target.RestoreHabit(ctx, habits[0].Id)).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.RestoreHabit(ctx, habits[2].Id)).To(Succeed())
+			Expect(target.PurgeHabits(ctx, deletedAt.Add(time.Hour))).To(BeZero())
+		})
+
+		It("deletes every habit of a user, including their trash, and only theirs", func() {
+			Expect(target.DeleteHabit(ctx, habits[0].Id, deletedAt)).To(Succeed())
+			Expect(target.DeleteHabitsByUserId(ctx, owner)).To(Succeed())
+			_, total, err := target.GetHabitsByUserId(ctx, owner, domain.ExcludeArchived, pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			_, total, err = target.GetDeletedHabitsByUserId(ctx, owner, pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(BeZero())
+			Expect(target.DeleteHabitsByUserId(ctx, owner)).To(Succeed())
+			Expect(target.DeleteHabitsByUserId(ctx, primitive.NewObjectID())).To(Succeed())
+		})
+
+		It("updates habits at the expected version", func() {
+			updated := habits[0]
+			updated.Name = "read more"
+			updated.Cadence = domain.Month
+			updated.RepeatingDays = nil
+			updated.Streak = 4
+			updated.BestStreak = 6
+			updated.CheckIns = 9
+			Expect(target.UpdateHabit(ctx, updated)).To(Succeed())
+
+			updated.Version = 2
+			Expect(target.GetHabitById(ctx, updated.Id)).To(Equal(updated))
+		})
+
+		It("rejects updates based on a stale version", func() {
+			Expect(target.UpdateHabit(ctx, habits[0])).To(Succeed())
+			stale := habits[0]
+			stale.Name = "stale"
+			Expect(target.UpdateHabit(ctx, stale)).To(MatchError(cadence_errors.ErrStaleVersion))
+			Expect(target.GetHabitById(ctx, habits[0].Id)).To(HaveField("Name", habits[0].Name))
+		})
+
+		It("reports updates of unknown habits as not found", func() {
+			unknown := habits[0]
+			unknown.Id = primitive.NewObjectID()
+			Expect(target.UpdateHabit(ctx, unknown)).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("applies exactly one of several concurrent updates to the same version", func() {
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				updated int
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					habit := habits[1]
+					habit.Streak++
+					err := target.UpdateHabit(ctx, habit)
+					mu.Lock()
+					defer mu.Unlock()
+					if err == nil {
+						updated++
+					} else {
+						Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(updated).To(Equal(1))
+			Expect(target.GetHabitById(ctx, habits[1].Id)).To(HaveField("Streak", uint32(1)))
+		})
+
+		It("fails with an internal error once the context is done", func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := target.GetHabitById(cancelled, habits[0].Id)
+			Expect(err).NotTo(BeNil())
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Internal))
+		})
+
+		It("keeps every habit created concurrently", func() {
+			user := primitive.NewObjectID()
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					habit := domain.Habit{Id: primitive.NewObjectID(), Name: "parallel", UserId: user, RepeatingDays: []uint16{}}
+					Expect(target.CreateHabit(ctx, habit)).To(Succeed())
+				}()
+			}
+			wg.Wait()
+
+			_, total, err := target.GetHabitsByUserId(ctx, user, domain.ExcludeArchived, pagination.Page{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(int64(20)))
+		})
+	})
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HabitRepository persists habits. Implementations must translate storage specific failures into cadence_errors
+// kinds, returning a NotFound error when no habit matches. Habits in the trash are only seen by the methods that say
+// so.
+//
+//go:generate mockgen --source=habit_repository.go --destination=mocks/mock_dependencies.go --package=mocks
+type HabitRepository interface {
+	CreateHabit(ctx context.Context, habit domain.Habit) error
+	GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error)
+	// GetHabitsByUserId returns a page of the user's habits selected by archived, ordered by id, along with the total
+	// number of habits selected.
+	GetHabitsByUserId(
+		ctx context.Context,
+		userId primitive.ObjectID,
+		archived domain.ArchivedFilter,
+		page pagination.Page,
+	) ([]domain.Habit, int64, error)
+	// GetHabitStats summarises the user's habits selected by archived from the totals kept on each habit.
+	GetHabitStats(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter) (domain.Stats, error)
+	// UpdateHabit replaces the stored habit if its version is still habit.Version, storing the habit with the version
+	// incremented. It returns cadence_errors.ErrStaleVersion when the stored version differs.
+	UpdateHabit(ctx context.Context, habit domain.Habit) error
+	// DeleteHabit moves the habit to the trash, recording deletedAt as its DeletedAt.
+	DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error
+	// GetDeletedHabitsByUserId returns a page of the user's habits in the trash, most recently deleted first, along
+	// with the total number of habits in the user's trash.
+	GetDeletedHabitsByUserId(ctx context.Context, userId primitive.ObjectID, page pagination.Page) ([]domain.Habit, int64, error)
+	// RestoreHabit takes the habit out of the trash, returning a NotFound error when it is not in the trash.
+	RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error
+	// PurgeHabits permanently deletes the habits moved to the trash before deletedBefore, returning how many there
+	// were.
+	PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// DeleteHabitsByUserId permanently deletes every habit the user has, including those in the trash, succeeding
+	// when they have none.
+	DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type checkInRepository struct {
+	mu       sync.RWMutex
+	checkIns map[primitive.ObjectID]domain.CheckIn
+}
+
+func NewCheckInRepository() repositories.CheckInRepository {
+	return &checkInRepository{checkIns: map[primitive.ObjectID]domain.CheckIn{}}
+}
+
+func (r *checkInRepository) CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.checkIns[checkIn.Id]; ok {
+		return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+	}
+	for _, stored := range r.checkIns {
+		if stored.HabitId == checkIn.HabitId && stored.Date.Equal(checkIn.Date) {
+			return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+		}
+	}
+	r.checkIns[checkIn.Id] = checkIn
+	return nil
+}
+
+func (r *checkInRepository) GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return domain.CheckIn{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checkIns := r.byHabit(habitId)
+	if len(checkIns) == 0 {
+		return domain.CheckIn{}, cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	return checkIns[0], nil
+}
+
+func (r *checkInRepository) GetCheckInsByHabitId(
+	ctx context.Context,
+	habitId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.CheckIn, int64, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return nil, 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checkIns := r.byHabit(habitId)
+	total := int64(len(checkIns))
+	start := min(page.Offset, len(checkIns))
+	end := min(start+page.Limit, len(checkIns))
+	return append([]domain.CheckIn{}, checkIns[start:end]...), total, nil
+}
+
+// byHabit returns the habit's check-ins outside the trash, latest first. The caller must hold the lock.
+func (r *checkInRepository) byHabit(habitId primitive.ObjectID) []domain.CheckIn {
+	checkIns := []domain.CheckIn{}
+	for _, checkIn := range r.checkIns {
+		if checkIn.HabitId == habitId && checkIn.DeletedAt == nil {
+			checkIns = append(checkIns, checkIn)
+		}
+	}
+	sort.Slice(checkIns, func(i, j int) bool {
+		return checkIns[i].Date.After(checkIns[j].Date)
+	})
+	return checkIns
+}
+
+func (r *checkInRepository) DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, checkIn := range r.checkIns {
+		if checkIn.HabitId == habitId && checkIn.DeletedAt == nil {
+			checkIn.DeletedAt = &deletedAt
+			r.checkIns[id] = checkIn
+		}
+	}
+	return nil
+}
+
+func (r *checkInRepository) RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, checkIn := range r.checkIns {
+		if checkIn.HabitId == habitId {
+			checkIn.DeletedAt = nil
+			r.checkIns[id] = checkIn
+		}
+	}
+	return nil
+}
+
+func (r *checkInRepository) PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := r.deleteWhere(ctx, func(checkIn domain.CheckIn) bool {
+		if checkIn.DeletedAt != nil && checkIn.DeletedAt.Before(deletedBefore) {
+			purged++
+			return true
+		}
+		return false
+	})
+	return purged, err
+}
+
+func (r *checkInRepository) DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	return r.deleteWhere(ctx, func(checkIn domain.CheckIn) bool { return checkIn.UserId == userId })
+}
+
+func (r *checkInRepository) deleteWhere(ctx context.Context, match func(domain.CheckIn) bool) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, checkIn := range r.checkIns {
+		if match(checkIn) {
+			delete(r.checkIns, id)
+		}
+	}
+	return nil
+}
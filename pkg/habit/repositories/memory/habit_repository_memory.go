@@ -0,0 +1,248 @@
+// Package memory keeps habits in process memory. It behaves like the Mongo repository, down to the errors it returns,
+// and is meant for tests, demos and running the API without a database.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type habitRepository struct {
+	mu     sync.RWMutex
+	habits map[primitive.ObjectID]domain.Habit
+}
+
+func NewHabitRepository() repositories.HabitRepository {
+	return &habitRepository{habits: map[primitive.ObjectID]domain.Habit{}}
+}
+
+// clone copies the habit's slices and pointers so that callers cannot modify stored habits.
+func clone(habit domain.Habit) domain.Habit {
+	if habit.RepeatingDays != nil {
+		habit.RepeatingDays = append([]uint16{}, habit.RepeatingDays...)
+	}
+	if habit.DeletedAt != nil {
+		deletedAt := *habit.DeletedAt
+		habit.DeletedAt = &deletedAt
+	}
+	if habit.ArchivedAt != nil {
+		archivedAt := *habit.ArchivedAt
+		habit.ArchivedAt = &archivedAt
+	}
+	return habit
+}
+
+// active returns the habit with id unless it is missing or in the trash. The caller must hold the lock.
+func (r *habitRepository) active(habitId primitive.ObjectID) (domain.Habit, bool) {
+	habit, ok := r.habits[habitId]
+	return habit, ok && habit.DeletedAt == nil
+}
+
+func (r *habitRepository) CreateHabit(ctx context.Context, habit domain.Habit) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.habits[habit.Id]; ok {
+		return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+	}
+	r.habits[habit.Id] = clone(habit)
+	return nil
+}
+
+func (r *habitRepository) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return domain.Habit{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	habit, ok := r.active(habitId)
+	if !ok {
+		return domain.Habit{}, cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	return clone(habit), nil
+}
+
+func (r *habitRepository) GetHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, page, func(habit domain.Habit) bool {
+		return habit.UserId == userId && habit.DeletedAt == nil && archived.Matches(habit)
+	}, func(a, b domain.Habit) bool {
+		return bytes.Compare(a.Id[:], b.Id[:]) < 0
+	})
+}
+
+func (r *habitRepository) GetDeletedHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, page, func(habit domain.Habit) bool {
+		return habit.UserId == userId && habit.DeletedAt != nil
+	}, func(a, b domain.Habit) bool {
+		if !a.DeletedAt.Equal(*b.DeletedAt) {
+			return a.DeletedAt.After(*b.DeletedAt)
+		}
+		return bytes.Compare(a.Id[:], b.Id[:]) < 0
+	})
+}
+
+// list returns a page of the habits matching match in the order of less, along with the number of matches.
+func (r *habitRepository) list(
+	ctx context.Context,
+	page pagination.Page,
+	match func(domain.Habit) bool,
+	less func(a, b domain.Habit) bool,
+) ([]domain.Habit, int64, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return nil, 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owned := []domain.Habit{}
+	for _, habit := range r.habits {
+		if match(habit) {
+			owned = append(owned, habit)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return less(owned[i], owned[j])
+	})
+
+	total := int64(len(owned))
+	start := min(page.Offset, len(owned))
+	end := min(start+page.Limit, len(owned))
+	habits := make([]domain.Habit, 0, end-start)
+	for _, habit := range owned[start:end] {
+		habits = append(habits, clone(habit))
+	}
+	return habits, total, nil
+}
+
+func (r *habitRepository) GetHabitStats(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+) (domain.Stats, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return domain.Stats{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stats domain.Stats
+	for _, habit := range r.habits {
+		if habit.UserId != userId || habit.DeletedAt != nil || !archived.Matches(habit) {
+			continue
+		}
+		stats.Habits++
+		if habit.ArchivedAt != nil {
+			stats.Archived++
+		}
+		stats.CheckIns += habit.CheckIns
+		stats.LongestStreak = max(stats.LongestStreak, habit.BestStreak)
+	}
+	return stats, nil
+}
+
+func (r *habitRepository) UpdateHabit(ctx context.Context, habit domain.Habit) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.active(habit.Id)
+	if !ok {
+		return cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	if stored.Version != habit.Version {
+		return cadence_errors.ErrStaleVersion
+	}
+	habit.Version++
+	habit.DeletedAt = nil
+	r.habits[habit.Id] = clone(habit)
+	return nil
+}
+
+func (r *habitRepository) DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	habit, ok := r.active(habitId)
+	if !ok {
+		return cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	habit.DeletedAt = &deletedAt
+	r.habits[habitId] = habit
+	return nil
+}
+
+func (r *habitRepository) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	habit, ok := r.habits[habitId]
+	if !ok || habit.DeletedAt == nil {
+		return cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	habit.DeletedAt = nil
+	r.habits[habitId] = habit
+	return nil
+}
+
+func (r *habitRepository) PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, habit := range r.habits {
+		if habit.DeletedAt != nil && habit.DeletedAt.Before(deletedBefore) {
+			delete(r.habits, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *habitRepository) DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, habit := range r.habits {
+		if habit.UserId == userId {
+			delete(r.habits, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: check_in_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	pagination "github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	domain "github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockCheckInRepository is a mock of CheckInRepository interface.
+type MockCheckInRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckInRepositoryMockRecorder
+}
+
+// MockCheckInRepositoryMockRecorder is the mock recorder for MockCheckInRepository.
+type MockCheckInRepositoryMockRecorder struct {
+	mock *MockCheckInRepository
+}
+
+// NewMockCheckInRepository creates a new mock instance.
+func NewMockCheckInRepository(ctrl *gomock.Controller) *MockCheckInRepository {
+	mock := &MockCheckInRepository{ctrl: ctrl}
+	mock.recorder = &MockCheckInRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCheckInRepository) EXPECT() *MockCheckInRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateCheckIn mocks base method.
+func (m *MockCheckInRepository) CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCheckIn", ctx, checkIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCheckIn indicates an expected call of CreateCheckIn.
+func (mr *MockCheckInRepositoryMockRecorder) CreateCheckIn(ctx, checkIn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckIn", reflect.TypeOf((*MockCheckInRepository)(nil).CreateCheckIn), ctx, checkIn)
+}
+
+// DeleteCheckInsByHabitId mocks base method.
+func (m *MockCheckInRepository) DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCheckInsByHabitId", ctx, habitId, deletedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCheckInsByHabitId indicates an expected call of DeleteCheckInsByHabitId.
+func (mr *MockCheckInRepositoryMockRecorder) DeleteCheckInsByHabitId(ctx, habitId, deletedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCheckInsByHabitId", reflect.TypeOf((*MockCheckInRepository)(nil).DeleteCheckInsByHabitId), ctx, habitId, deletedAt)
+}
+
+// DeleteCheckInsByUserId mocks base method.
+func (m *MockCheckInRepository) DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCheckInsByUserId", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCheckInsByUserId indicates an expected call of DeleteCheckInsByUserId.
+func (mr *MockCheckInRepositoryMockRecorder) DeleteCheckInsByUserId(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCheckInsByUserId", reflect.TypeOf((*MockCheckInRepository)(nil).DeleteCheckInsByUserId), ctx, userId)
+}
+
+// GetCheckInsByHabitId mocks base method.
+func (m *MockCheckInRepository) GetCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, page pagination.Page) ([]domain.CheckIn, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckInsByHabitId", ctx, habitId, page)
+	ret0, _ := ret[0].([]domain.CheckIn)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCheckInsByHabitId indicates an expected call of GetCheckInsByHabitId.
+func (mr *MockCheckInRepositoryMockRecorder) GetCheckInsByHabitId(ctx, habitId, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckInsByHabitId", reflect.TypeOf((*MockCheckInRepository)(nil).GetCheckInsByHabitId), ctx, habitId, page)
+}
+
+// GetLatestCheckIn mocks base method.
+func (m *MockCheckInRepository) GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestCheckIn", ctx, habitId)
+	ret0, _ := ret[0].(domain.CheckIn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestCheckIn indicates an expected call of GetLatestCheckIn.
+func (mr *MockCheckInRepositoryMockRecorder) GetLatestCheckIn(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestCheckIn", reflect.TypeOf((*MockCheckInRepository)(nil).GetLatestCheckIn), ctx, habitId)
+}
+
+// PurgeCheckIns mocks base method.
+func (m *MockCheckInRepository) PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeCheckIns", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeCheckIns indicates an expected call of PurgeCheckIns.
+func (mr *MockCheckInRepositoryMockRecorder) PurgeCheckIns(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeCheckIns", reflect.TypeOf((*MockCheckInRepository)(nil).PurgeCheckIns), ctx, deletedBefore)
+}
+
+// RestoreCheckInsByHabitId mocks base method.
+func (m *MockCheckInRepository) RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCheckInsByHabitId", ctx, habitId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCheckInsByHabitId indicates an expected call of RestoreCheckInsByHabitId.
+func (mr *MockCheckInRepositoryMockRecorder) RestoreCheckInsByHabitId(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCheckInsByHabitId", reflect.TypeOf((*MockCheckInRepository)(nil).RestoreCheckInsByHabitId), ctx, habitId)
+}
@@ -0,0 +1,186 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: habit_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	pagination "github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	domain "github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockHabitRepository is a mock of HabitRepository interface.
+type MockHabitRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHabitRepositoryMockRecorder
+}
+
+// MockHabitRepositoryMockRecorder is the mock recorder for MockHabitRepository.
+type MockHabitRepositoryMockRecorder struct {
+	mock *MockHabitRepository
+}
+
+// NewMockHabitRepository creates a new mock instance.
+func NewMockHabitRepository(ctrl *gomock.Controller) *MockHabitRepository {
+	mock := &MockHabitRepository{ctrl: ctrl}
+	mock.recorder = &MockHabitRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHabitRepository) EXPECT() *MockHabitRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateHabit mocks base method.
+func (m *MockHabitRepository) CreateHabit(ctx context.Context, habit domain.Habit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHabit", ctx, habit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateHabit indicates an expected call of CreateHabit.
+func (mr *MockHabitRepositoryMockRecorder) CreateHabit(ctx, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHabit", reflect.TypeOf((*MockHabitRepository)(nil).CreateHabit), ctx, habit)
+}
+
+// DeleteHabit mocks base method.
+func (m *MockHabitRepository) DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHabit", ctx, habitId, deletedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHabit indicates an expected call of DeleteHabit.
+func (mr *MockHabitRepositoryMockRecorder) DeleteHabit(ctx, habitId, deletedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHabit", reflect.TypeOf((*MockHabitRepository)(nil).DeleteHabit), ctx, habitId, deletedAt)
+}
+
+// DeleteHabitsByUserId mocks base method.
+func (m *MockHabitRepository) DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHabitsByUserId", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHabitsByUserId indicates an expected call of DeleteHabitsByUserId.
+func (mr *MockHabitRepositoryMockRecorder) DeleteHabitsByUserId(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHabitsByUserId", reflect.TypeOf((*MockHabitRepository)(nil).DeleteHabitsByUserId), ctx, userId)
+}
+
+// GetDeletedHabitsByUserId mocks base method.
+func (m *MockHabitRepository) GetDeletedHabitsByUserId(ctx context.Context, userId primitive.ObjectID, page pagination.Page) ([]domain.Habit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedHabitsByUserId", ctx, userId, page)
+	ret0, _ := ret[0].([]domain.Habit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDeletedHabitsByUserId indicates an expected call of GetDeletedHabitsByUserId.
+func (mr *MockHabitRepositoryMockRecorder) GetDeletedHabitsByUserId(ctx, userId, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedHabitsByUserId", reflect.TypeOf((*MockHabitRepository)(nil).GetDeletedHabitsByUserId), ctx, userId, page)
+}
+
+// GetHabitById mocks base method.
+func (m *MockHabitRepository) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitById", ctx, habitId)
+	ret0, _ := ret[0].(domain.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitById indicates an expected call of GetHabitById.
+func (mr *MockHabitRepositoryMockRecorder) GetHabitById(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitById", reflect.TypeOf((*MockHabitRepository)(nil).GetHabitById), ctx, habitId)
+}
+
+// GetHabitStats mocks base method.
+func (m *MockHabitRepository) GetHabitStats(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter) (domain.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitStats", ctx, userId, archived)
+	ret0, _ := ret[0].(domain.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitStats indicates an expected call of GetHabitStats.
+func (mr *MockHabitRepositoryMockRecorder) GetHabitStats(ctx, userId, archived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitStats", reflect.TypeOf((*MockHabitRepository)(nil).GetHabitStats), ctx, userId, archived)
+}
+
+// GetHabitsByUserId mocks base method.
+func (m *MockHabitRepository) GetHabitsByUserId(ctx context.Context, userId primitive.ObjectID, archived domain.ArchivedFilter, page pagination.Page) ([]domain.Habit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitsByUserId", ctx, userId, archived, page)
+	ret0, _ := ret[0].([]domain.Habit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetHabitsByUserId indicates an expected call of GetHabitsByUserId.
+func (mr *MockHabitRepositoryMockRecorder) GetHabitsByUserId(ctx, userId, archived, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitsByUserId", reflect.TypeOf((*MockHabitRepository)(nil).GetHabitsByUserId), ctx, userId, archived, page)
+}
+
+// PurgeHabits mocks base method.
+func (m *MockHabitRepository) PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeHabits", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeHabits indicates an expected call of PurgeHabits.
+func (mr *MockHabitRepositoryMockRecorder) PurgeHabits(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeHabits", reflect.TypeOf((*MockHabitRepository)(nil).PurgeHabits), ctx, deletedBefore)
+}
+
+// RestoreHabit mocks base method.
+func (m *MockHabitRepository) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreHabit", ctx, habitId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreHabit indicates an expected call of RestoreHabit.
+func (mr *MockHabitRepositoryMockRecorder) RestoreHabit(ctx, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreHabit", reflect.TypeOf((*MockHabitRepository)(nil).RestoreHabit), ctx, habitId)
+}
+
+// UpdateHabit mocks base method.
+func (m *MockHabitRepository) UpdateHabit(ctx context.Context, habit domain.Habit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHabit", ctx, habit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHabit indicates an expected call of UpdateHabit.
+func (mr *MockHabitRepositoryMockRecorder) UpdateHabit(ctx, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHabit", reflect.TypeOf((*MockHabitRepository)(nil).UpdateHabit), ctx, habit)
+}
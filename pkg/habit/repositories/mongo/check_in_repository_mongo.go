@@ -0,0 +1,121 @@
+package mongo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// latestFirst orders check-ins by date, most recent first.
+var latestFirst = bson.D{{Key: "date", Value: -1}}
+
+type checkInRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewCheckInRepository stores check-ins in collection, which relies on the habit_id_date_unique index for one
+// check-in per habit and day.
+func NewCheckInRepository(collection *mongo.Collection, logger *slog.Logger) repositories.CheckInRepository {
+	return &checkInRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+func (r *checkInRepository) translateError(ctx context.Context, operation string, err error) error {
+	return mongodb.TranslateAndLog(ctx, r.logger, r.collection.Name(), operation, err)
+}
+
+func (r *checkInRepository) CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error {
+	if _, err := r.collection.InsertOne(ctx, checkIn); err != nil {
+		return r.translateError(ctx, "insert check-in", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error) {
+	checkIn := &domain.CheckIn{}
+	err := r.collection.FindOne(ctx,
+		bson.D{{Key: "habit_id", Value: habitId}, notDeleted},
+		options.FindOne().SetSort(latestFirst),
+	).Decode(checkIn)
+	if err != nil {
+		return domain.CheckIn{}, r.translateError(ctx, "find latest check-in", err)
+	}
+	return *checkIn, nil
+}
+
+func (r *checkInRepository) GetCheckInsByHabitId(
+	ctx context.Context,
+	habitId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.CheckIn, int64, error) {
+	filter := bson.D{{Key: "habit_id", Value: habitId}, notDeleted}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count check-ins by habit", err)
+	}
+
+	opts := options.Find().
+		SetSort(latestFirst).
+		SetSkip(int64(page.Offset)).
+		SetLimit(int64(page.Limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find check-ins by habit", err)
+	}
+
+	checkIns := []domain.CheckIn{}
+	if err = cursor.All(ctx, &checkIns); err != nil {
+		return nil, 0, r.translateError(ctx, "decode check-ins", err)
+	}
+	return checkIns, total, nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.D{{Key: "habit_id", Value: habitId}, notDeleted},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: deletedAt}}}},
+	)
+	if err != nil {
+		return r.translateError(ctx, "delete check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.D{{Key: "habit_id", Value: habitId}, inTrash},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "deleted_at", Value: ""}}}},
+	)
+	if err != nil {
+		return r.translateError(ctx, "restore check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, deletedBeforeFilter(deletedBefore))
+	if err != nil {
+		return 0, r.translateError(ctx, "purge check-ins", err)
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.D{{Key: "user_id", Value: userId}}); err != nil {
+		return r.translateError(ctx, "delete check-ins by user", err)
+	}
+	return nil
+}
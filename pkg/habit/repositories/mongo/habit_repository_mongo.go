@@ -0,0 +1,226 @@
+package mongo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notDeleted matches documents outside the trash, which have no deleted_at.
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+
+// inTrash matches documents in the trash.
+var inTrash = bson.E{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}}
+
+type habitRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+func NewHabitRepository(collection *mongo.Collection, logger *slog.Logger) repositories.HabitRepository {
+	return &habitRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+func (r *habitRepository) translateError(ctx context.Context, operation string, err error) error {
+	return mongodb.TranslateAndLog(ctx, r.logger, r.collection.Name(), operation, err)
+}
+
+func (r *habitRepository) CreateHabit(ctx context.Context, habit domain.Habit) error {
+	_, err := r.collection.InsertOne(ctx, habit)
+	if err != nil {
+		return r.translateError(ctx, "insert habit", err)
+	}
+	return nil
+}
+
+func (r *habitRepository) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	habit := &domain.Habit{}
+	err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: habitId}, notDeleted}).Decode(habit)
+	if err != nil {
+		return domain.Habit{}, r.translateError(ctx, "find habit by id", err)
+	}
+	return *habit, nil
+}
+
+func (r *habitRepository) GetHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "habits by user",
+		userHabitsFilter(userId, archived),
+		bson.D{{Key: "_id", Value: 1}},
+		page,
+	)
+}
+
+func (r *habitRepository) GetDeletedHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "deleted habits by user",
+		bson.D{{Key: "user_id", Value: userId}, inTrash},
+		bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: 1}},
+		page,
+	)
+}
+
+// list returns a page of the habits matching filter in sort order, along with the number of matches.
+func (r *habitRepository) list(
+	ctx context.Context,
+	what string,
+	filter, sort bson.D,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count "+what, err)
+	}
+
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(int64(page.Offset)).
+		SetLimit(int64(page.Limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find "+what, err)
+	}
+
+	habits := []domain.Habit{}
+	if err = cursor.All(ctx, &habits); err != nil {
+		return nil, 0, r.translateError(ctx, "decode habits", err)
+	}
+	return habits, total, nil
+}
+
+func (r *habitRepository) GetHabitStats(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+) (domain.Stats, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: userHabitsFilter(userId, archived)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "habits", Value: bson.D{{Key: "$sum", Value: int64(1)}}},
+			{Key: "archived", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$archived_at", nil}}}, int64(1), int64(0),
+			}}}}}},
+			{Key: "check_ins", Value: bson.D{{Key: "$sum", Value: "$check_ins"}}},
+			{Key: "longest_streak", Value: bson.D{{Key: "$max", Value: "$best_streak"}}},
+		}}},
+	})
+	if err != nil {
+		return domain.Stats{}, r.translateError(ctx, "summarise habits by user", err)
+	}
+
+	var results []struct {
+		Habits        int64  `bson:"habits"`
+		Archived      int64  `bson:"archived"`
+		CheckIns      int64  `bson:"check_ins"`
+		LongestStreak uint32 `bson:"longest_streak"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return domain.Stats{}, r.translateError(ctx, "decode habit stats", err)
+	}
+	// a user without habits has no group at all
+	if len(results) == 0 {
+		return domain.Stats{}, nil
+	}
+	return domain.Stats{
+		Habits:        results[0].Habits,
+		Archived:      results[0].Archived,
+		CheckIns:      results[0].CheckIns,
+		LongestStreak: results[0].LongestStreak,
+	}, nil
+}
+
+func (r *habitRepository) UpdateHabit(ctx context.Context, habit domain.Habit) error {
+	filter := bson.D{{Key: "_id", Value: habit.Id}, notDeleted}
+	versioned := bson.D{{Key: "_id", Value: habit.Id}, notDeleted, {Key: "version", Value: habit.Version}}
+	habit.Version++
+	habit.DeletedAt = nil
+	result, err := r.collection.ReplaceOne(ctx, versioned, habit)
+	if err != nil {
+		return r.translateError(ctx, "update habit", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongodb.MissingOrStale(ctx, r.collection, filter)
+	}
+	return nil
+}
+
+func (r *habitRepository) DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: habitId}, notDeleted},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: deletedAt}}}},
+	)
+	if err != nil {
+		return r.translateError(ctx, "delete habit", err)
+	}
+	if result.MatchedCount == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *habitRepository) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: habitId}, inTrash},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "deleted_at", Value: ""}}}},
+	)
+	if err != nil {
+		return r.translateError(ctx, "restore habit", err)
+	}
+	if result.MatchedCount == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *habitRepository) PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, deletedBeforeFilter(deletedBefore))
+	if err != nil {
+		return 0, r.translateError(ctx, "purge habits", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// userHabitsFilter matches the user's habits outside the trash selected by archived.
+func userHabitsFilter(userId primitive.ObjectID, archived domain.ArchivedFilter) bson.D {
+	filter := bson.D{{Key: "user_id", Value: userId}, notDeleted}
+	switch archived {
+	case domain.ExcludeArchived:
+		filter = append(filter, bson.E{Key: "archived_at", Value: nil})
+	case domain.OnlyArchived:
+		filter = append(filter, bson.E{Key: "archived_at", Value: bson.D{{Key: "$ne", Value: nil}}})
+	}
+	return filter
+}
+
+// deletedBeforeFilter matches documents moved to the trash before t.
+func deletedBeforeFilter(t time.Time) bson.D {
+	return bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$lt", Value: t}}}}
+}
+
+func (r *habitRepository) DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.D{{Key: "user_id", Value: userId}}); err != nil {
+		return r.translateError(ctx, "delete habits by user", err)
+	}
+	return nil
+}
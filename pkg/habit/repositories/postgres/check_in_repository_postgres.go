@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	commonPostgres "github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const checkInColumns = "id, habit_id, user_id, date, streak, deleted_at"
+
+type checkInRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewCheckInRepository stores check-ins in the check_ins table.
+func NewCheckInRepository(pool *pgxpool.Pool, logger *slog.Logger) repositories.CheckInRepository {
+	return &checkInRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *checkInRepository) conn(ctx context.Context) commonPostgres.Queryer {
+	return commonPostgres.Conn(ctx, r.pool)
+}
+
+func (r *checkInRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonPostgres.TranslateAndLog(ctx, r.logger, "check_ins", operation, err)
+}
+
+func (r *checkInRepository) CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error {
+	_, err := r.conn(ctx).Exec(ctx,
+		"INSERT INTO check_ins ("+checkInColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		checkIn.Id.Hex(), checkIn.HabitId.Hex(), checkIn.UserId.Hex(), checkIn.Date, int64(checkIn.Streak),
+		checkIn.DeletedAt,
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert check-in", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error) {
+	rows, err := r.conn(ctx).Query(ctx,
+		"SELECT "+checkInColumns+" FROM check_ins WHERE habit_id = $1 AND deleted_at IS NULL ORDER BY date DESC LIMIT 1",
+		habitId.Hex(),
+	)
+	if err != nil {
+		return domain.CheckIn{}, r.translateError(ctx, "find latest check-in", err)
+	}
+	checkIn, err := pgx.CollectExactlyOneRow(rows, scanCheckIn)
+	if err != nil {
+		return domain.CheckIn{}, r.translateError(ctx, "find latest check-in", err)
+	}
+	return checkIn, nil
+}
+
+func (r *checkInRepository) GetCheckInsByHabitId(
+	ctx context.Context,
+	habitId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.CheckIn, int64, error) {
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT count(*) FROM check_ins WHERE habit_id = $1 AND deleted_at IS NULL", habitId.Hex()).Scan(&total)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count check-ins by habit", err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx,
+		"SELECT "+checkInColumns+" FROM check_ins WHERE habit_id = $1 AND deleted_at IS NULL ORDER BY date DESC LIMIT $2 OFFSET $3",
+		habitId.Hex(), page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find check-ins by habit", err)
+	}
+	checkIns, err := pgx.CollectRows(rows, scanCheckIn)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "decode check-ins", err)
+	}
+	if checkIns == nil {
+		checkIns = []domain.CheckIn{}
+	}
+	return checkIns, total, nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	_, err := r.conn(ctx).Exec(ctx,
+		"UPDATE check_ins SET deleted_at = $2 WHERE habit_id = $1 AND deleted_at IS NULL",
+		habitId.Hex(), deletedAt,
+	)
+	if err != nil {
+		return r.translateError(ctx, "delete check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error {
+	_, err := r.conn(ctx).Exec(ctx,
+		"UPDATE check_ins SET deleted_at = NULL WHERE habit_id = $1 AND deleted_at IS NOT NULL",
+		habitId.Hex(),
+	)
+	if err != nil {
+		return r.translateError(ctx, "restore check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := r.conn(ctx).Exec(ctx, "DELETE FROM check_ins WHERE deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, r.translateError(ctx, "purge check-ins", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.conn(ctx).Exec(ctx, "DELETE FROM check_ins WHERE user_id = $1", userId.Hex()); err != nil {
+		return r.translateError(ctx, "delete check-ins by user", err)
+	}
+	return nil
+}
+
+func scanCheckIn(row pgx.CollectableRow) (domain.CheckIn, error) {
+	var (
+		checkIn             domain.CheckIn
+		id, habitId, userId string
+		streak              int64
+	)
+	if err := row.Scan(&id, &habitId, &userId, &checkIn.Date, &streak, &checkIn.DeletedAt); err != nil {
+		return domain.CheckIn{}, err
+	}
+	var err error
+	if checkIn.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.CheckIn{}, err
+	}
+	if checkIn.HabitId, err = primitive.ObjectIDFromHex(habitId); err != nil {
+		return domain.CheckIn{}, err
+	}
+	if checkIn.UserId, err = primitive.ObjectIDFromHex(userId); err != nil {
+		return domain.CheckIn{}, err
+	}
+	checkIn.Streak = uint32(streak)
+	checkIn.DeletedAt = inUTC(checkIn.DeletedAt)
+	return checkIn, nil
+}
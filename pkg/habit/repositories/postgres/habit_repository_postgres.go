@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	commonPostgres "github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const habitColumns = "id, user_id, name, cadence, repeating_days, streak, version, deleted_at, archived_at, best_streak, check_ins"
+
+type habitRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewHabitRepository stores habits in the habits table.
+func NewHabitRepository(pool *pgxpool.Pool, logger *slog.Logger) repositories.HabitRepository {
+	return &habitRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *habitRepository) conn(ctx context.Context) commonPostgres.Queryer {
+	return commonPostgres.Conn(ctx, r.pool)
+}
+
+func (r *habitRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonPostgres.TranslateAndLog(ctx, r.logger, "habits", operation, err)
+}
+
+func (r *habitRepository) CreateHabit(ctx context.Context, habit domain.Habit) error {
+	_, err := r.conn(ctx).Exec(ctx,
+		"INSERT INTO habits ("+habitColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		habit.Id.Hex(), habit.UserId.Hex(), habit.Name, int16(habit.Cadence), daysColumn(habit), int64(habit.Streak),
+		habit.Version, habit.DeletedAt, habit.ArchivedAt, int64(habit.BestStreak), habit.CheckIns,
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert habit", err)
+	}
+	return nil
+}
+
+func (r *habitRepository) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	rows, err := r.conn(ctx).Query(ctx, "SELECT "+habitColumns+" FROM habits WHERE id = $1 AND deleted_at IS NULL", habitId.Hex())
+	if err != nil {
+		return domain.Habit{}, r.translateError(ctx, "find habit by id", err)
+	}
+	habit, err := pgx.CollectExactlyOneRow(rows, scanHabit)
+	if err != nil {
+		return domain.Habit{}, r.translateError(ctx, "find habit by id", err)
+	}
+	return habit, nil
+}
+
+func (r *habitRepository) GetHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "habits by user", "user_id = $1 AND deleted_at IS NULL"+archivedCondition(archived), "id", userId, page)
+}
+
+func (r *habitRepository) GetDeletedHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "deleted habits by user", "user_id = $1 AND deleted_at IS NOT NULL", "deleted_at DESC, id", userId, page)
+}
+
+// list returns a page of the user's habits matching where in the given order, along with the number of matches.
+func (r *habitRepository) list(
+	ctx context.Context,
+	what, where, orderBy string,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT count(*) FROM habits WHERE "+where, userId.Hex()).Scan(&total)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count "+what, err)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx,
+		"SELECT "+habitColumns+" FROM habits WHERE "+where+" ORDER BY "+orderBy+" LIMIT $2 OFFSET $3",
+		userId.Hex(), page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find "+what, err)
+	}
+	habits, err := pgx.CollectRows(rows, scanHabit)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "decode habits", err)
+	}
+	if habits == nil {
+		habits = []domain.Habit{}
+	}
+	return habits, total, nil
+}
+
+func (r *habitRepository) GetHabitStats(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+) (domain.Stats, error) {
+	var (
+		stats         domain.Stats
+		longestStreak int64
+	)
+	err := r.conn(ctx).QueryRow(ctx,
+		`SELECT count(*), count(archived_at), coalesce(sum(check_ins), 0)::BIGINT, coalesce(max(best_streak), 0)
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL`+archivedCondition(archived),
+		userId.Hex(),
+	).Scan(&stats.Habits, &stats.Archived, &stats.CheckIns, &longestStreak)
+	if err != nil {
+		return domain.Stats{}, r.translateError(ctx, "summarise habits by user", err)
+	}
+	stats.LongestStreak = uint32(longestStreak)
+	return stats, nil
+}
+
+func (r *habitRepository) UpdateHabit(ctx context.Context, habit domain.Habit) error {
+	tag, err := r.conn(ctx).Exec(ctx,
+		`UPDATE habits SET user_id = $3, name = $4, cadence = $5, repeating_days = $6, streak = $7, archived_at = $8,
+		best_streak = $9, check_ins = $10, version = version + 1
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL`,
+		habit.Id.Hex(), habit.Version,
+		habit.UserId.Hex(), habit.Name, int16(habit.Cadence), daysColumn(habit), int64(habit.Streak), habit.ArchivedAt,
+		int64(habit.BestStreak), habit.CheckIns,
+	)
+	if err != nil {
+		return r.translateError(ctx, "update habit", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missingOrStale(ctx, "update habit", habit.Id)
+	}
+	return nil
+}
+
+// missingOrStale explains why a conditional update matched no rows.
+func (r *habitRepository) missingOrStale(ctx context.Context, operation string, habitId primitive.ObjectID) error {
+	var exists bool
+	if err := r.conn(ctx).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM habits WHERE id = $1 AND deleted_at IS NULL)", habitId.Hex()).Scan(&exists); err != nil {
+		return r.translateError(ctx, operation, err)
+	}
+	if !exists {
+		return cadence_errors.ErrNotFound
+	}
+	return cadence_errors.ErrStaleVersion
+}
+
+func (r *habitRepository) DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE habits SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL",
+		habitId.Hex(), deletedAt,
+	)
+	if err != nil {
+		return r.translateError(ctx, "delete habit", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *habitRepository) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE habits SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL",
+		habitId.Hex(),
+	)
+	if err != nil {
+		return r.translateError(ctx, "restore habit", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *habitRepository) PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := r.conn(ctx).Exec(ctx, "DELETE FROM habits WHERE deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, r.translateError(ctx, "purge habits", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *habitRepository) DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.conn(ctx).Exec(ctx, "DELETE FROM habits WHERE user_id = $1", userId.Hex()); err != nil {
+		return r.translateError(ctx, "delete habits by user", err)
+	}
+	return nil
+}
+
+// archivedCondition narrows a habits query to the habits selected by archived.
+func archivedCondition(archived domain.ArchivedFilter) string {
+	switch archived {
+	case domain.ExcludeArchived:
+		return " AND archived_at IS NULL"
+	case domain.OnlyArchived:
+		return " AND archived_at IS NOT NULL"
+	default:
+		return ""
+	}
+}
+
+// daysColumn converts the habit's days to the int32 elements of the INTEGER[] column, keeping nil as NULL.
+func daysColumn(habit domain.Habit) []int32 {
+	if habit.RepeatingDays == nil {
+		return nil
+	}
+	days := make([]int32, len(habit.RepeatingDays))
+	for i, day := range habit.RepeatingDays {
+		days[i] = int32(day)
+	}
+	return days
+}
+
+func scanHabit(row pgx.CollectableRow) (domain.Habit, error) {
+	var (
+		habit         domain.Habit
+		id, userId    string
+		cadence       int16
+		repeatingDays []int32
+		streak        int64
+		bestStreak    int64
+	)
+	if err := row.Scan(&id, &userId, &habit.Name, &cadence, &repeatingDays, &streak, &habit.Version, &habit.DeletedAt,
+		&habit.ArchivedAt, &bestStreak, &habit.CheckIns); err != nil {
+		return domain.Habit{}, err
+	}
+	var err error
+	if habit.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.Habit{}, err
+	}
+	if habit.UserId, err = primitive.ObjectIDFromHex(userId); err != nil {
+		return domain.Habit{}, err
+	}
+	habit.Cadence = domain.Cadence(cadence)
+	habit.Streak = uint32(streak)
+	habit.BestStreak = uint32(bestStreak)
+	if repeatingDays != nil {
+		habit.RepeatingDays = make([]uint16, len(repeatingDays))
+		for i, day := range repeatingDays {
+			habit.RepeatingDays[i] = uint16(day)
+		}
+	}
+	habit.DeletedAt = inUTC(habit.DeletedAt)
+	habit.ArchivedAt = inUTC(habit.ArchivedAt)
+	return habit, nil
+}
+
+// inUTC converts a timestamp read from a TIMESTAMPTZ column, which pgx returns in the local time zone, to UTC.
+func inUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
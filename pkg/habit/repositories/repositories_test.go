@@ -0,0 +1,64 @@
+package repositories_test
+
+import (
+	"context"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb/mongotest"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres/pgtest"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite/sqlitetest"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories/contract"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories/memory"
+	habitMongo "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/mongo"
+	habitPostgres "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/postgres"
+	habitSQLite "github.com/alexander-littleton/cadence-api/pkg/habit/repositories/sqlite"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ = contract.HabitRepository("memory", memory.NewHabitRepository)
+var _ = contract.CheckInRepository("memory", memory.NewCheckInRepository)
+
+var _ = contract.HabitRepository("mongo", func() repositories.HabitRepository {
+	return habitMongo.NewHabitRepository(mongotest.Database().Collection("habits"), logging.Discard())
+})
+var _ = contract.CheckInRepository("mongo", func() repositories.CheckInRepository {
+	db := mongotest.Database()
+	// one check-in per habit and day is enforced by the database rather than the repository
+	_, err := mongodb.NewMigrator(db, mongodb.Migrations).Up(context.TODO())
+	Expect(err).To(BeNil())
+	return habitMongo.NewCheckInRepository(db.Collection("check_ins"), logging.Discard())
+})
+
+var _ = contract.HabitRepository("postgres", func() repositories.HabitRepository {
+	return habitPostgres.NewHabitRepository(pgtest.Database(dsn), logging.Discard())
+})
+var _ = contract.CheckInRepository("postgres", func() repositories.CheckInRepository {
+	return habitPostgres.NewCheckInRepository(pgtest.Database(dsn), logging.Discard())
+})
+
+var _ = contract.HabitRepository("sqlite", func() repositories.HabitRepository {
+	return habitSQLite.NewHabitRepository(sqlitetest.Database(), logging.Discard())
+})
+var _ = contract.CheckInRepository("sqlite", func() repositories.CheckInRepository {
+	return habitSQLite.NewCheckInRepository(sqlitetest.Database(), logging.Discard())
+})
+
+var _ = Describe("memory HabitRepository", func() {
+	It("does not share slices with callers", func() {
+		ctx := context.TODO()
+		target := memory.NewHabitRepository()
+		habit := domain.Habit{Id: primitive.NewObjectID(), UserId: primitive.NewObjectID(), RepeatingDays: []uint16{1, 3}}
+		Expect(target.CreateHabit(ctx, habit)).To(Succeed())
+		habit.RepeatingDays[1] = 5
+
+		found, err := target.GetHabitById(ctx, habit.Id)
+		Expect(err).To(BeNil())
+		found.RepeatingDays[0] = 6
+		Expect(target.GetHabitById(ctx, habit.Id)).To(HaveField("RepeatingDays", []uint16{1, 3}))
+	})
+})
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	commonSQLite "github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const checkInColumns = "id, habit_id, user_id, date, streak, deleted_at"
+
+type checkInRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewCheckInRepository stores check-ins in the check_ins table of an SQLite database. Dates are kept as YYYY-MM-DD.
+func NewCheckInRepository(db *sql.DB, logger *slog.Logger) repositories.CheckInRepository {
+	return &checkInRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *checkInRepository) conn(ctx context.Context) commonSQLite.Queryer {
+	return commonSQLite.Conn(ctx, r.db)
+}
+
+func (r *checkInRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonSQLite.TranslateAndLog(ctx, r.logger, "check_ins", operation, err)
+}
+
+func (r *checkInRepository) CreateCheckIn(ctx context.Context, checkIn domain.CheckIn) error {
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO check_ins ("+checkInColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		checkIn.Id.Hex(), checkIn.HabitId.Hex(), checkIn.UserId.Hex(), checkIn.Date.Format(time.DateOnly),
+		int64(checkIn.Streak), timestampColumn(checkIn.DeletedAt),
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert check-in", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) GetLatestCheckIn(ctx context.Context, habitId primitive.ObjectID) (domain.CheckIn, error) {
+	row := r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+checkInColumns+" FROM check_ins WHERE habit_id = ? AND deleted_at IS NULL ORDER BY date DESC LIMIT 1",
+		habitId.Hex(),
+	)
+	checkIn, err := scanCheckIn(row)
+	if err != nil {
+		return domain.CheckIn{}, r.translateError(ctx, "find latest check-in", err)
+	}
+	return checkIn, nil
+}
+
+func (r *checkInRepository) GetCheckInsByHabitId(
+	ctx context.Context,
+	habitId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.CheckIn, int64, error) {
+	var total int64
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT count(*) FROM check_ins WHERE habit_id = ? AND deleted_at IS NULL", habitId.Hex()).Scan(&total)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count check-ins by habit", err)
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+checkInColumns+" FROM check_ins WHERE habit_id = ? AND deleted_at IS NULL ORDER BY date DESC LIMIT ? OFFSET ?",
+		habitId.Hex(), page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find check-ins by habit", err)
+	}
+	defer rows.Close()
+
+	checkIns := []domain.CheckIn{}
+	for rows.Next() {
+		checkIn, err := scanCheckIn(rows)
+		if err != nil {
+			return nil, 0, r.translateError(ctx, "decode check-ins", err)
+		}
+		checkIns = append(checkIns, checkIn)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, r.translateError(ctx, "find check-ins by habit", err)
+	}
+	return checkIns, total, nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"UPDATE check_ins SET deleted_at = ? WHERE habit_id = ? AND deleted_at IS NULL",
+		timestampColumn(&deletedAt), habitId.Hex(),
+	)
+	if err != nil {
+		return r.translateError(ctx, "delete check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) RestoreCheckInsByHabitId(ctx context.Context, habitId primitive.ObjectID) error {
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"UPDATE check_ins SET deleted_at = NULL WHERE habit_id = ? AND deleted_at IS NOT NULL",
+		habitId.Hex(),
+	)
+	if err != nil {
+		return r.translateError(ctx, "restore check-ins by habit", err)
+	}
+	return nil
+}
+
+func (r *checkInRepository) PurgeCheckIns(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM check_ins WHERE deleted_at < ?", timestampColumn(&deletedBefore))
+	if err != nil {
+		return 0, r.translateError(ctx, "purge check-ins", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.translateError(ctx, "purge check-ins", err)
+	}
+	return purged, nil
+}
+
+func (r *checkInRepository) DeleteCheckInsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM check_ins WHERE user_id = ?", userId.Hex()); err != nil {
+		return r.translateError(ctx, "delete check-ins by user", err)
+	}
+	return nil
+}
+
+// scanCheckIn reads a row selected with checkInColumns from either *sql.Row or *sql.Rows.
+func scanCheckIn(row interface{ Scan(...any) error }) (domain.CheckIn, error) {
+	var (
+		checkIn             domain.CheckIn
+		id, habitId, userId string
+		date                string
+		streak              int64
+		deletedAt           sql.NullString
+	)
+	if err := row.Scan(&id, &habitId, &userId, &date, &streak, &deletedAt); err != nil {
+		return domain.CheckIn{}, err
+	}
+	var err error
+	if checkIn.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.CheckIn{}, err
+	}
+	if checkIn.HabitId, err = primitive.ObjectIDFromHex(habitId); err != nil {
+		return domain.CheckIn{}, err
+	}
+	if checkIn.UserId, err = primitive.ObjectIDFromHex(userId); err != nil {
+		return domain.CheckIn{}, err
+	}
+	if checkIn.Date, err = time.Parse(time.DateOnly, date); err != nil {
+		return domain.CheckIn{}, err
+	}
+	checkIn.Streak = uint32(streak)
+	if checkIn.DeletedAt, err = parseTimestamp(deletedAt); err != nil {
+		return domain.CheckIn{}, err
+	}
+	return checkIn, nil
+}
@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/pagination"
+	commonSQLite "github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/habit/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const habitColumns = "id, user_id, name, cadence, repeating_days, streak, version, deleted_at, archived_at, best_streak, check_ins"
+
+// timestampLayout formats deleted_at and archived_at in UTC with a fixed width, so that timestamps compare as strings.
+const timestampLayout = "2006-01-02T15:04:05.000000Z"
+
+type habitRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewHabitRepository stores habits in the habits table of an SQLite database. Repeating days are kept as a JSON array.
+func NewHabitRepository(db *sql.DB, logger *slog.Logger) repositories.HabitRepository {
+	return &habitRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *habitRepository) conn(ctx context.Context) commonSQLite.Queryer {
+	return commonSQLite.Conn(ctx, r.db)
+}
+
+func (r *habitRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonSQLite.TranslateAndLog(ctx, r.logger, "habits", operation, err)
+}
+
+func (r *habitRepository) CreateHabit(ctx context.Context, habit domain.Habit) error {
+	days, err := daysColumn(habit)
+	if err != nil {
+		return r.translateError(ctx, "encode habit", err)
+	}
+	_, err = r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO habits ("+habitColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		habit.Id.Hex(), habit.UserId.Hex(), habit.Name, int64(habit.Cadence), days, int64(habit.Streak), habit.Version,
+		timestampColumn(habit.DeletedAt), timestampColumn(habit.ArchivedAt), int64(habit.BestStreak), habit.CheckIns,
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert habit", err)
+	}
+	return nil
+}
+
+func (r *habitRepository) GetHabitById(ctx context.Context, habitId primitive.ObjectID) (domain.Habit, error) {
+	row := r.conn(ctx).QueryRowContext(ctx, "SELECT "+habitColumns+" FROM habits WHERE id = ? AND deleted_at IS NULL", habitId.Hex())
+	habit, err := scanHabit(row)
+	if err != nil {
+		return domain.Habit{}, r.translateError(ctx, "find habit by id", err)
+	}
+	return habit, nil
+}
+
+func (r *habitRepository) GetHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "habits by user", "user_id = ? AND deleted_at IS NULL"+archivedCondition(archived), "id", userId, page)
+}
+
+func (r *habitRepository) GetDeletedHabitsByUserId(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	return r.list(ctx, "deleted habits by user", "user_id = ? AND deleted_at IS NOT NULL", "deleted_at DESC, id", userId, page)
+}
+
+// list returns a page of the user's habits matching where in the given order, along with the number of matches.
+func (r *habitRepository) list(
+	ctx context.Context,
+	what, where, orderBy string,
+	userId primitive.ObjectID,
+	page pagination.Page,
+) ([]domain.Habit, int64, error) {
+	var total int64
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT count(*) FROM habits WHERE "+where, userId.Hex()).Scan(&total)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "count "+what, err)
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+habitColumns+" FROM habits WHERE "+where+" ORDER BY "+orderBy+" LIMIT ? OFFSET ?",
+		userId.Hex(), page.Limit, page.Offset,
+	)
+	if err != nil {
+		return nil, 0, r.translateError(ctx, "find "+what, err)
+	}
+	defer rows.Close()
+
+	habits := []domain.Habit{}
+	for rows.Next() {
+		habit, err := scanHabit(rows)
+		if err != nil {
+			return nil, 0, r.translateError(ctx, "decode habits", err)
+		}
+		habits = append(habits, habit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, r.translateError(ctx, "find "+what, err)
+	}
+	return habits, total, nil
+}
+
+func (r *habitRepository) GetHabitStats(
+	ctx context.Context,
+	userId primitive.ObjectID,
+	archived domain.ArchivedFilter,
+) (domain.Stats, error) {
+	var (
+		stats         domain.Stats
+		longestStreak int64
+	)
+	err := r.conn(ctx).QueryRowContext(ctx,
+		`SELECT count(*), count(archived_at), coalesce(sum(check_ins), 0), coalesce(max(best_streak), 0)
+		FROM habits WHERE user_id = ? AND deleted_at IS NULL`+archivedCondition(archived),
+		userId.Hex(),
+	).Scan(&stats.Habits, &stats.Archived, &stats.CheckIns, &longestStreak)
+	if err != nil {
+		return domain.Stats{}, r.translateError(ctx, "summarise habits by user", err)
+	}
+	stats.LongestStreak = uint32(longestStreak)
+	return stats, nil
+}
+
+func (r *habitRepository) UpdateHabit(ctx context.Context, habit domain.Habit) error {
+	days, err := daysColumn(habit)
+	if err != nil {
+		return r.translateError(ctx, "encode habit", err)
+	}
+	result, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE habits SET user_id = ?, name = ?, cadence = ?, repeating_days = ?, streak = ?, archived_at = ?,
+		best_streak = ?, check_ins = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL`,
+		habit.UserId.Hex(), habit.Name, int64(habit.Cadence), days, int64(habit.Streak), timestampColumn(habit.ArchivedAt),
+		int64(habit.BestStreak), habit.CheckIns,
+		habit.Id.Hex(), habit.Version,
+	)
+	if err != nil {
+		return r.translateError(ctx, "update habit", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return r.translateError(ctx, "update habit", err)
+	} else if affected == 0 {
+		return r.missingOrStale(ctx, "update habit", habit.Id)
+	}
+	return nil
+}
+
+// missingOrStale explains why a conditional update matched no rows.
+func (r *habitRepository) missingOrStale(ctx context.Context, operation string, habitId primitive.ObjectID) error {
+	var exists bool
+	if err := r.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM habits WHERE id = ? AND deleted_at IS NULL)", habitId.Hex()).Scan(&exists); err != nil {
+		return r.translateError(ctx, operation, err)
+	}
+	if !exists {
+		return cadence_errors.ErrNotFound
+	}
+	return cadence_errors.ErrStaleVersion
+}
+
+func (r *habitRepository) DeleteHabit(ctx context.Context, habitId primitive.ObjectID, deletedAt time.Time) error {
+	return r.setDeletedAt(ctx, "delete habit",
+		"UPDATE habits SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		timestampColumn(&deletedAt), habitId.Hex(),
+	)
+}
+
+func (r *habitRepository) RestoreHabit(ctx context.Context, habitId primitive.ObjectID) error {
+	return r.setDeletedAt(ctx, "restore habit",
+		"UPDATE habits SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		habitId.Hex(),
+	)
+}
+
+// setDeletedAt runs an update of a single habit's deleted_at, returning a NotFound error when it matched nothing.
+func (r *habitRepository) setDeletedAt(ctx context.Context, operation, query string, args ...any) error {
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return r.translateError(ctx, operation, err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return r.translateError(ctx, operation, err)
+	} else if affected == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *habitRepository) PurgeHabits(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM habits WHERE deleted_at < ?", timestampColumn(&deletedBefore))
+	if err != nil {
+		return 0, r.translateError(ctx, "purge habits", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.translateError(ctx, "purge habits", err)
+	}
+	return purged, nil
+}
+
+func (r *habitRepository) DeleteHabitsByUserId(ctx context.Context, userId primitive.ObjectID) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM habits WHERE user_id = ?", userId.Hex()); err != nil {
+		return r.translateError(ctx, "delete habits by user", err)
+	}
+	return nil
+}
+
+// archivedCondition narrows a habits query to the habits selected by archived.
+func archivedCondition(archived domain.ArchivedFilter) string {
+	switch archived {
+	case domain.ExcludeArchived:
+		return " AND archived_at IS NULL"
+	case domain.OnlyArchived:
+		return " AND archived_at IS NOT NULL"
+	default:
+		return ""
+	}
+}
+
+// daysColumn encodes the habit's days as a JSON array, keeping nil as NULL.
+func daysColumn(habit domain.Habit) (sql.NullString, error) {
+	if habit.RepeatingDays == nil {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(habit.RepeatingDays)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// timestampColumn formats t with timestampLayout, keeping nil as NULL.
+func timestampColumn(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(timestampLayout), Valid: true}
+}
+
+// parseTimestamp reads a column written by timestampColumn.
+func parseTimestamp(column sql.NullString) (*time.Time, error) {
+	if !column.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(timestampLayout, column.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// scanHabit reads a row selected with habitColumns from either *sql.Row or *sql.Rows.
+func scanHabit(row interface{ Scan(...any) error }) (domain.Habit, error) {
+	var (
+		habit         domain.Habit
+		id, userId    string
+		cadence       int64
+		repeatingDays sql.NullString
+		streak        int64
+		deletedAt     sql.NullString
+		archivedAt    sql.NullString
+		bestStreak    int64
+	)
+	if err := row.Scan(&id, &userId, &habit.Name, &cadence, &repeatingDays, &streak, &habit.Version, &deletedAt, &archivedAt,
+		&bestStreak, &habit.CheckIns); err != nil {
+		return domain.Habit{}, err
+	}
+	var err error
+	if habit.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.Habit{}, err
+	}
+	if habit.UserId, err = primitive.ObjectIDFromHex(userId); err != nil {
+		return domain.Habit{}, err
+	}
+	habit.Cadence = domain.Cadence(cadence)
+	habit.Streak = uint32(streak)
+	habit.BestStreak = uint32(bestStreak)
+	if repeatingDays.Valid {
+		if err = json.Unmarshal([]byte(repeatingDays.String), &habit.RepeatingDays); err != nil {
+			return domain.Habit{}, err
+		}
+	}
+	if habit.DeletedAt, err = parseTimestamp(deletedAt); err != nil {
+		return domain.Habit{}, err
+	}
+	if habit.ArchivedAt, err = parseTimestamp(archivedAt); err != nil {
+		return domain.Habit{}, err
+	}
+	return habit, nil
+}
@@ -1,19 +1,19 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: user_controller.go
+// Source: user_service.go
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
 	context "context"
-	models "github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	reflect "reflect"
 
+	domain "github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	gomock "github.com/golang/mock/gomock"
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// MockUserService is a mock of UserService interface.
+// MockUserService is a mock of Service interface.
 type MockUserService struct {
 	ctrl     *gomock.Controller
 	recorder *MockUserServiceMockRecorder
@@ -37,10 +37,10 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 }
 
 // CreateUser mocks base method.
-func (m *MockUserService) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+func (m *MockUserService) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "createUser", ctx, user)
-	ret0, _ := ret[0].(models.User)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -48,14 +48,28 @@ func (m *MockUserService) CreateUser(ctx context.Context, user models.User) (mod
 // CreateUser indicates an expected call of CreateUser.
 func (mr *MockUserServiceMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "createUser", reflect.TypeOf((*MockUserService)(nil).CreateUser), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserService)(nil).CreateUser), ctx, user)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserService) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserServiceMockRecorder) DeleteUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserService)(nil).DeleteUser), ctx, userId)
 }
 
 // GetUserByEmail mocks base method.
-func (m *MockUserService) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+func (m *MockUserService) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
-	ret0, _ := ret[0].(models.User)
+	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -67,10 +81,10 @@ func (mr *MockUserServiceMockRecorder) GetUserByEmail(ctx, email interface{}) *g
 }
 
 // GetUserById mocks base method.
-func (m *MockUserService) GetUserById(ctx context.Context, userId primitive.ObjectID) (models.User, error) {
+func (m *MockUserService) GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUserById", ctx, userId)
-	ret0, _ := ret[0].(models.User)
+	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -80,3 +94,18 @@ func (mr *MockUserServiceMockRecorder) GetUserById(ctx, userId interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockUserService)(nil).GetUserById), ctx, userId)
 }
+
+// UpdateUser mocks base method.
+func (m *MockUserService) UpdateUser(ctx context.Context, user domain.User) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserServiceMockRecorder) UpdateUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserService)(nil).UpdateUser), ctx, user)
+}
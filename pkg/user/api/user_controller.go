@@ -1,14 +1,17 @@
 package api
 
 import (
-	"errors"
-	"fmt"
+	"net/http"
+
 	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/etag"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
+	"github.com/alexander-littleton/cadence-api/pkg/common/response"
 	userService "github.com/alexander-littleton/cadence-api/pkg/user"
 	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"net/http"
 )
 
 type Controller struct {
@@ -21,104 +24,177 @@ func New(userService userService.Service) Controller {
 	}
 }
 
-func (r Controller) RegisterRoutes(router *gin.Engine) {
+// RegisterRoutes mounts the user endpoints. Errors are attached to the gin context and rendered by
+// middleware.ErrorHandler, which must be installed on the router.
+func (r Controller) RegisterRoutes(router gin.IRouter) {
 	router.POST("/user", r.createUser)
 	router.GET("/user/:email", r.GetUserByEmail)
+	router.PUT("/user/:userId", r.updateUser)
+	router.DELETE("/user/:userId", r.deleteUser)
+}
+
+// Operations documents the routes mounted by RegisterRoutes for the OpenAPI spec.
+func (r Controller) Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method:  http.MethodPost,
+			Path:    "/user",
+			Summary: "Create a user",
+			Tags:    []string{"users"},
+			Request: domain.User{},
+			Responses: map[int]openapi.Response{
+				http.StatusCreated: {
+					Description: "The created user",
+					Body:        response.Envelope[domain.User]{},
+					LegacyBody:  response.Legacy[domain.User]{},
+				},
+			},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/user/:email",
+			Summary: "Get a user by email",
+			Tags:    []string{"users"},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "The user",
+					Body:        response.Envelope[domain.User]{},
+					LegacyBody:  response.Legacy[domain.User]{},
+				},
+			},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/user/:userId",
+			Summary: "Change a user's email",
+			Tags:    []string{"users"},
+			Request: domain.User{},
+			Responses: map[int]openapi.Response{
+				http.StatusOK: {
+					Description: "The updated user",
+					Body:        response.Envelope[domain.User]{},
+					LegacyBody:  response.Legacy[domain.User]{},
+				},
+				http.StatusConflict:           {Description: "The email is taken or the user was updated concurrently"},
+				http.StatusPreconditionFailed: {Description: "The If-Match header does not match the user's current ETag"},
+			},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/user/:userId",
+			Summary: "Delete a user along with their habits and check-ins",
+			Tags:    []string{"users"},
+			Responses: map[int]openapi.Response{
+				http.StatusNoContent: {Description: "The user was deleted"},
+			},
+		},
+	}
 }
 
 func (r Controller) createUser(ctx *gin.Context) {
 	var newUser domain.User
-	if err := ctx.BindJSON(&newUser); err != nil {
-		ctx.JSON(http.StatusBadRequest, domain.UserResponse{
-			Status:  http.StatusBadRequest,
-			Message: "error",
-			Data: map[string]interface{}{
-				"data": fmt.Sprint("failed to unmarshal new user from request body: ", err.Error()),
-			},
-		})
+	if err := ctx.ShouldBindJSON(&newUser); err != nil {
+		_ = ctx.Error(cadence_errors.FromBinding(err, "failed to unmarshal new user from request body"))
 		return
 	}
 
 	createdUser, err := r.userService.CreateUser(ctx, newUser)
 	if err != nil {
-		var status int
-		if errors.Is(err, cadence_errors.ValidationErr) {
-			status = http.StatusBadRequest
-		} else {
-			status = http.StatusInternalServerError
-		}
-		ctx.JSON(
-			status,
-			domain.UserResponse{
-				Status:  status,
-				Message: "error",
-				Data:    map[string]interface{}{"data": err.Error()},
-			},
-		)
+		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(
-		http.StatusCreated,
-		domain.UserResponse{
-			Status:  http.StatusCreated,
-			Message: "success",
-			Data:    map[string]interface{}{"data": createdUser},
-		},
-	)
-	return
+	middleware.SetUserId(ctx, createdUser.Id)
+	etag.Set(ctx, createdUser.Version)
+	respond(ctx, http.StatusCreated, createdUser)
 }
 
 func (r Controller) GetUserById(ctx *gin.Context) {
 	rawId := ctx.Param("userId")
-	objId, _ := primitive.ObjectIDFromHex(rawId)
+	objId, err := primitive.ObjectIDFromHex(rawId)
+	if err != nil {
+		_ = ctx.Error(cadence_errors.Wrap(err, cadence_errors.Validation, "user.id_invalid", "invalid user id"))
+		return
+	}
 
 	user, err := r.userService.GetUserById(ctx, objId)
 	if err != nil {
-		ctx.JSON(
-			http.StatusInternalServerError,
-			domain.UserResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "error",
-				Data:    map[string]interface{}{"data": err.Error()},
-			},
-		)
+		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		domain.UserResponse{
-			Status:  http.StatusOK,
-			Message: "success",
-			Data:    map[string]interface{}{"data": user},
-		},
-	)
+	etag.Set(ctx, user.Version)
+	respond(ctx, http.StatusOK, user)
 }
 
 func (r Controller) GetUserByEmail(ctx *gin.Context) {
 	email := ctx.Param("email")
 
 	user, err := r.userService.GetUserByEmail(ctx, email)
-	//TODO: handle validation errors
 	if err != nil {
-		ctx.JSON(
-			http.StatusInternalServerError,
-			domain.UserResponse{
-				Status:  http.StatusInternalServerError,
-				Message: "error",
-				Data:    map[string]interface{}{"data": err.Error()},
-			},
-		)
+		_ = ctx.Error(err)
 		return
 	}
+	middleware.SetUserId(ctx, user.Id)
 
-	ctx.JSON(
-		http.StatusOK,
-		domain.UserResponse{
-			Status:  http.StatusOK,
-			Message: "success",
-			Data:    map[string]interface{}{"data": user},
-		},
-	)
+	etag.Set(ctx, user.Version)
+	respond(ctx, http.StatusOK, user)
+}
+
+func (r Controller) updateUser(ctx *gin.Context) {
+	userId, err := primitive.ObjectIDFromHex(ctx.Param("userId"))
+	if err != nil {
+		_ = ctx.Error(cadence_errors.Wrap(err, cadence_errors.Validation, "user.id_invalid", "invalid user id"))
+		return
+	}
+	version, conditional, err := etag.IfMatch(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var user domain.User
+	if err = ctx.ShouldBindJSON(&user); err != nil {
+		_ = ctx.Error(cadence_errors.FromBinding(err, "failed to unmarshal user from request body"))
+		return
+	}
+	user.Id = userId
+	if conditional {
+		user.Version = version
+	}
+
+	updatedUser, err := r.userService.UpdateUser(ctx, user)
+	if err != nil {
+		if conditional {
+			err = etag.Precondition(err)
+		}
+		_ = ctx.Error(err)
+		return
+	}
+
+	etag.Set(ctx, updatedUser.Version)
+	respond(ctx, http.StatusOK, updatedUser)
+}
+
+func (r Controller) deleteUser(ctx *gin.Context) {
+	userId, err := primitive.ObjectIDFromHex(ctx.Param("userId"))
+	if err != nil {
+		_ = ctx.Error(cadence_errors.Wrap(err, cadence_errors.Validation, "user.id_invalid", "invalid user id"))
+		return
+	}
+
+	if err = r.userService.DeleteUser(ctx, userId); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respond renders user in the shape of the route being served: the unversioned routes keep the body old clients parse.
+func respond(ctx *gin.Context, status int, user domain.User) {
+	if middleware.IsLegacy(ctx) {
+		ctx.JSON(status, response.LegacySuccess(status, user))
+		return
+	}
+	ctx.JSON(status, response.Success(ctx, status, user))
 }
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/middleware"
+	"github.com/alexander-littleton/cadence-api/pkg/common/openapi"
 	"github.com/alexander-littleton/cadence-api/pkg/user/api"
 	"github.com/alexander-littleton/cadence-api/pkg/user/api/mocks"
 	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
@@ -30,6 +33,7 @@ var _ = Describe("Main", func() {
 	BeforeEach(func() {
 		w = httptest.NewRecorder()
 		router = gin.New()
+		router.Use(middleware.ErrorHandler())
 		ctrl = gomock.NewController(GinkgoT())
 		userService = mocks.NewMockUserService(ctrl)
 		target = api.New(userService)
@@ -37,6 +41,13 @@ var _ = Describe("Main", func() {
 
 	})
 
+	It("documents exactly the routes it registers", func() {
+		spec := openapi.NewGenerator(openapi.Info{Title: "test", Version: "1"})
+		spec.Add(target)
+		_, err := spec.Generate(router.Routes())
+		Expect(err).To(BeNil())
+	})
+
 	Context("create new user", func() {
 		var newUser domain.User
 		JustBeforeEach(func() {
@@ -77,5 +88,172 @@ var _ = Describe("Main", func() {
 				Expect(w.Code).To(Equal(500))
 			})
 		})
+		Context("a user with the email already exists", func() {
+			BeforeEach(func() {
+				newUser = domain.User{Email: "test@test.com"}
+				userService.EXPECT().CreateUser(gomock.Any(), newUser).
+					Return(domain.User{}, cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "taken"))
+			})
+			It("returns a 409 with the error code", func() {
+				Expect(w.Code).To(Equal(409))
+				Expect(w.Body.String()).To(ContainSubstring("user.email_taken"))
+			})
+		})
+	})
+
+	Context("create new user with a malformed body", func() {
+		BeforeEach(func() {
+			request, _ := http.NewRequest("POST", "/user", bytes.NewReader([]byte("{")))
+			router.ServeHTTP(w, request)
+		})
+		It("returns a 400", func() {
+			Expect(w.Code).To(Equal(400))
+		})
+	})
+
+	Context("get user by email", func() {
+		var (
+			email  string
+			accept string
+		)
+		BeforeEach(func() {
+			accept = ""
+		})
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("GET", "/user/"+email, nil)
+			request.Header.Set("Accept", accept)
+			router.ServeHTTP(w, request)
+		})
+		Context("the user exists", func() {
+			BeforeEach(func() {
+				email = "test@test.com"
+				userService.EXPECT().GetUserByEmail(gomock.Any(), email).
+					Return(domain.User{Id: primitive.NewObjectID(), Email: email, Version: 2}, nil)
+			})
+			It("returns a 200 with the user's ETag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(w.Header().Get("ETag")).To(Equal(`"2"`))
+			})
+		})
+		Context("the user does not exist", func() {
+			BeforeEach(func() {
+				email = "missing@test.com"
+				userService.EXPECT().GetUserByEmail(gomock.Any(), email).
+					Return(domain.User{}, fmt.Errorf("failed to get user: %w", cadence_errors.ErrNotFound))
+			})
+			It("returns a 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
+		Context("the client accepts problem details", func() {
+			BeforeEach(func() {
+				email = "not-an-email"
+				accept = "application/problem+json"
+				userService.EXPECT().GetUserByEmail(gomock.Any(), email).Return(
+					domain.User{},
+					fmt.Errorf("wrapped: %w", cadence_errors.New(cadence_errors.Validation, "user.email_invalid", "invalid email").
+						WithField("email", "must be a valid email address")),
+				)
+			})
+			It("returns an RFC 7807 document without internal error text", func() {
+				Expect(w.Code).To(Equal(400))
+				Expect(w.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+
+				var body map[string]interface{}
+				Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+				Expect(body).To(HaveKeyWithValue("status", BeNumerically("==", 400)))
+				Expect(body).To(HaveKeyWithValue("detail", "invalid email"))
+				Expect(body).To(HaveKeyWithValue("instance", "/user/"+email))
+				Expect(body).To(HaveKeyWithValue("code", "user.email_invalid"))
+				Expect(body).To(HaveKey("invalid_params"))
+				Expect(w.Body.String()).NotTo(ContainSubstring("wrapped"))
+			})
+		})
+		Context("the email is invalid", func() {
+			BeforeEach(func() {
+				email = "not-an-email"
+				userService.EXPECT().GetUserByEmail(gomock.Any(), email).
+					Return(domain.User{}, cadence_errors.ValidationErr)
+			})
+			It("returns a 400", func() {
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+	})
+
+	Context("update user", func() {
+		var (
+			userId  primitive.ObjectID
+			ifMatch string
+		)
+		BeforeEach(func() {
+			userId = primitive.NewObjectID()
+			ifMatch = ""
+		})
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("PUT", "/user/"+userId.Hex(), bytes.NewReader([]byte(`{"email":"new@test.com"}`)))
+			if ifMatch != "" {
+				request.Header.Set("If-Match", ifMatch)
+			}
+			router.ServeHTTP(w, request)
+		})
+		Context("If-Match names the current version", func() {
+			BeforeEach(func() {
+				ifMatch = `"1"`
+				userService.EXPECT().UpdateUser(gomock.Any(), domain.User{Id: userId, Email: "new@test.com", Version: 1}).
+					Return(domain.User{Id: userId, Email: "new@test.com", Version: 2}, nil)
+			})
+			It("returns the user with its new ETag", func() {
+				Expect(w.Code).To(Equal(200))
+				Expect(w.Header().Get("ETag")).To(Equal(`"2"`))
+			})
+		})
+		Context("If-Match names an older version", func() {
+			BeforeEach(func() {
+				ifMatch = `"1"`
+				userService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(domain.User{}, cadence_errors.ErrStaleVersion)
+			})
+			It("returns a 412", func() {
+				Expect(w.Code).To(Equal(412))
+			})
+		})
+		Context("the email is taken", func() {
+			BeforeEach(func() {
+				ifMatch = `"1"`
+				userService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).
+					Return(domain.User{}, cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "taken"))
+			})
+			It("returns a 409 rather than a failed precondition", func() {
+				Expect(w.Code).To(Equal(409))
+				Expect(w.Body.String()).To(ContainSubstring("user.email_taken"))
+			})
+		})
+	})
+
+	Context("delete user", func() {
+		var userId primitive.ObjectID
+		BeforeEach(func() {
+			userId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			request, _ := http.NewRequest("DELETE", "/user/"+userId.Hex(), nil)
+			router.ServeHTTP(w, request)
+		})
+		Context("the user exists", func() {
+			BeforeEach(func() {
+				userService.EXPECT().DeleteUser(gomock.Any(), userId).Return(nil)
+			})
+			It("returns a 204", func() {
+				Expect(w.Code).To(Equal(204))
+			})
+		})
+		Context("the user does not exist", func() {
+			BeforeEach(func() {
+				userService.EXPECT().DeleteUser(gomock.Any(), userId).Return(cadence_errors.ErrNotFound)
+			})
+			It("returns a 404", func() {
+				Expect(w.Code).To(Equal(404))
+			})
+		})
 	})
 })
@@ -5,12 +5,8 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 type User struct {
 	Id    primitive.ObjectID `json:"id" bson:"_id"`
 	Email string             `json:"email,omitempty" validate:"required"`
-}
-
-type UserResponse struct {
-	Status  int                    `json:"status"`
-	Message string                 `json:"message"`
-	Data    map[string]interface{} `json:"data"`
+	// Version is incremented by every update and guards against concurrent updates overwriting each other.
+	Version int64 `json:"version" bson:"version"`
 }
 
 type CreateUserRequest struct {
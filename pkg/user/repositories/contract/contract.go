@@ -0,0 +1,163 @@
+// Package contract is the behaviour every repositories.UserRepository must share, written as Ginkgo specs so that
+// the repositories suite can run it against every backend.
+package contract
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepository registers the contract specs for the named backend. newRepository is called before every spec and
+// must return an empty repository.
+func UserRepository(backend string, newRepository func() repositories.UserRepository) bool {
+	return Describe(backend+" UserRepository contract", func() {
+		var (
+			target repositories.UserRepository
+			ctx    context.Context
+			user   domain.User
+		)
+
+		BeforeEach(func() {
+			target = newRepository()
+			ctx = context.TODO()
+			user = domain.User{Id: primitive.NewObjectID(), Email: "contract@test.com", Version: 1}
+			Expect(target.CreateUser(ctx, user)).To(Succeed())
+		})
+
+		It("finds users by id and email", func() {
+			Expect(target.GetUserById(ctx, user.Id)).To(Equal(user))
+			Expect(target.GetUserByEmail(ctx, user.Email)).To(Equal(user))
+		})
+
+		It("returns not found for unknown users", func() {
+			_, err := target.GetUserById(ctx, primitive.NewObjectID())
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			_, err = target.GetUserByEmail(ctx, "nobody@test.com")
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("rejects a duplicate id", func() {
+			err := target.CreateUser(ctx, domain.User{Id: user.Id, Email: "other@test.com"})
+			Expect(err).To(MatchError(cadence_errors.ErrConflict))
+		})
+
+		It("rejects a second user with the same email", func() {
+			err := target.CreateUser(ctx, domain.User{Id: primitive.NewObjectID(), Email: user.Email})
+			Expect(err).To(MatchError(cadence_errors.ErrConflict))
+			Expect(target.GetUserByEmail(ctx, user.Email)).To(Equal(user))
+		})
+
+		It("updates users at the expected version", func() {
+			updated := user
+			updated.Email = "renamed@test.com"
+			Expect(target.UpdateUser(ctx, updated)).To(Succeed())
+
+			updated.Version = 2
+			Expect(target.GetUserById(ctx, user.Id)).To(Equal(updated))
+			Expect(target.GetUserByEmail(ctx, updated.Email)).To(Equal(updated))
+			_, err := target.GetUserByEmail(ctx, user.Email)
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("rejects updates based on a stale version", func() {
+			Expect(target.UpdateUser(ctx, user)).To(Succeed())
+			stale := user
+			stale.Email = "stale@test.com"
+			Expect(target.UpdateUser(ctx, stale)).To(MatchError(cadence_errors.ErrStaleVersion))
+			Expect(target.GetUserByEmail(ctx, user.Email)).To(HaveField("Version", int64(2)))
+		})
+
+		It("reports updates of unknown users as not found", func() {
+			err := target.UpdateUser(ctx, domain.User{Id: primitive.NewObjectID(), Email: "nobody@test.com", Version: 1})
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+		})
+
+		It("rejects an update to another user's email", func() {
+			other := domain.User{Id: primitive.NewObjectID(), Email: "other@test.com", Version: 1}
+			Expect(target.CreateUser(ctx, other)).To(Succeed())
+			other.Email = user.Email
+			Expect(target.UpdateUser(ctx, other)).To(MatchError(cadence_errors.ErrConflict))
+		})
+
+		It("applies exactly one of several concurrent updates to the same version", func() {
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				updated int
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					err := target.UpdateUser(ctx, domain.User{Id: user.Id, Email: fmt.Sprintf("update-%d@test.com", i), Version: 1})
+					mu.Lock()
+					defer mu.Unlock()
+					if err == nil {
+						updated++
+					} else {
+						Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+					}
+				}(i)
+			}
+			wg.Wait()
+			Expect(updated).To(Equal(1))
+			Expect(target.GetUserById(ctx, user.Id)).To(HaveField("Version", int64(2)))
+		})
+
+		It("deletes users, freeing their email, and reports unknown ones as not found", func() {
+			Expect(target.DeleteUser(ctx, user.Id)).To(Succeed())
+			_, err := target.GetUserById(ctx, user.Id)
+			Expect(err).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.DeleteUser(ctx, user.Id)).To(MatchError(cadence_errors.ErrNotFound))
+			Expect(target.CreateUser(ctx, domain.User{Id: primitive.NewObjectID(), Email: user.Email, Version: 1})).To(Succeed())
+		})
+
+		It("fails with an internal error once the context is done", func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := target.GetUserById(cancelled, user.Id)
+			Expect(err).NotTo(BeNil())
+			Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.Internal))
+		})
+
+		It("creates exactly one user per email under concurrent creates", func() {
+			var (
+				wg        sync.WaitGroup
+				mu        sync.Mutex
+				created   int
+				conflicts int
+			)
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					err := target.CreateUser(ctx, domain.User{
+						Id:    primitive.NewObjectID(),
+						Email: fmt.Sprintf("race-%d@test.com", i%2),
+					})
+					mu.Lock()
+					defer mu.Unlock()
+					if err == nil {
+						created++
+					} else {
+						Expect(err).To(MatchError(cadence_errors.ErrConflict))
+						conflicts++
+					}
+				}(i)
+			}
+			wg.Wait()
+			Expect(created).To(Equal(2))
+			Expect(conflicts).To(Equal(18))
+		})
+	})
+}
@@ -0,0 +1,113 @@
+// Package memory keeps users in process memory. It behaves like the Mongo repository, down to the errors it returns,
+// and is meant for tests, demos and running the API without a database.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/storage"
+	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type userRepository struct {
+	mu      sync.RWMutex
+	users   map[primitive.ObjectID]domain.User
+	byEmail map[string]primitive.ObjectID
+}
+
+func NewUserRepository() repositories.UserRepository {
+	return &userRepository{
+		users:   map[primitive.ObjectID]domain.User{},
+		byEmail: map[string]primitive.ObjectID{},
+	}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user domain.User) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.Id]; ok {
+		return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+	}
+	if _, ok := r.byEmail[user.Email]; ok {
+		return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+	}
+	r.users[user.Id] = user
+	r.byEmail[user.Email] = user.Id
+	return nil
+}
+
+func (r *userRepository) GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return domain.User{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userId]
+	if !ok {
+		return domain.User{}, cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
+	if err := storage.CheckContext(ctx); err != nil {
+		return domain.User{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byEmail[email]
+	if !ok {
+		return domain.User{}, cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	return r.users[id], nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.Id]
+	if !ok {
+		return cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	if stored.Version != user.Version {
+		return cadence_errors.ErrStaleVersion
+	}
+	if id, ok := r.byEmail[user.Email]; ok && id != user.Id {
+		return cadence_errors.New(cadence_errors.Conflict, "", "duplicate key")
+	}
+	delete(r.byEmail, stored.Email)
+	user.Version++
+	r.users[user.Id] = user
+	r.byEmail[user.Email] = user.Id
+	return nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	if err := storage.CheckContext(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[userId]
+	if !ok {
+		return cadence_errors.New(cadence_errors.NotFound, "", "not found")
+	}
+	delete(r.byEmail, stored.Email)
+	delete(r.users, userId)
+	return nil
+}
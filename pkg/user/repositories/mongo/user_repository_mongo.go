@@ -2,6 +2,10 @@ package mongo
 
 import (
 	"context"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
 	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,18 +15,24 @@ import (
 
 type userRepository struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
-func NewUserRepository(collection *mongo.Collection) repositories.UserRepository {
+func NewUserRepository(collection *mongo.Collection, logger *slog.Logger) repositories.UserRepository {
 	return &userRepository{
 		collection: collection,
+		logger:     logger,
 	}
 }
 
+func (r *userRepository) translateError(ctx context.Context, operation string, err error) error {
+	return mongodb.TranslateAndLog(ctx, r.logger, r.collection.Name(), operation, err)
+}
+
 func (r *userRepository) CreateUser(ctx context.Context, user domain.User) error {
 	_, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
-		return err
+		return r.translateError(ctx, "insert user", err)
 	}
 	return nil
 }
@@ -31,9 +41,8 @@ func (r *userRepository) GetUserById(ctx context.Context, userId primitive.Objec
 	user := &domain.User{}
 	err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: userId}}).Decode(user)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, r.translateError(ctx, "find user by id", err)
 	}
-	//TODO: ensure empty user is handled during error catch
 	return *user, nil
 }
 
@@ -41,7 +50,31 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (doma
 	user := &domain.User{}
 	err := r.collection.FindOne(ctx, bson.D{{Key: "email", Value: email}}).Decode(user)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, r.translateError(ctx, "find user by email", err)
 	}
 	return *user, nil
 }
+
+func (r *userRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	filter := bson.D{{Key: "_id", Value: user.Id}, {Key: "version", Value: user.Version}}
+	user.Version++
+	result, err := r.collection.ReplaceOne(ctx, filter, user)
+	if err != nil {
+		return r.translateError(ctx, "update user", err)
+	}
+	if result.MatchedCount == 0 {
+		return mongodb.MissingOrStale(ctx, r.collection, bson.D{{Key: "_id", Value: user.Id}})
+	}
+	return nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: userId}})
+	if err != nil {
+		return r.translateError(ctx, "delete user", err)
+	}
+	if result.DeletedCount == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	commonPostgres "github.com/alexander-littleton/cadence-api/pkg/common/postgres"
+	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type userRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewUserRepository stores users in the users table. Ids are kept as hex object ids so that they look the same to
+// clients whichever storage engine is used.
+func NewUserRepository(pool *pgxpool.Pool, logger *slog.Logger) repositories.UserRepository {
+	return &userRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *userRepository) conn(ctx context.Context) commonPostgres.Queryer {
+	return commonPostgres.Conn(ctx, r.pool)
+}
+
+func (r *userRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonPostgres.TranslateAndLog(ctx, r.logger, "users", operation, err)
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user domain.User) error {
+	_, err := r.conn(ctx).Exec(ctx, "INSERT INTO users (id, email, version) VALUES ($1, $2, $3)",
+		user.Id.Hex(), user.Email, user.Version,
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert user", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error) {
+	return r.getUser(ctx, "find user by id", "SELECT id, email, version FROM users WHERE id = $1", userId.Hex())
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
+	return r.getUser(ctx, "find user by email", "SELECT id, email, version FROM users WHERE email = $1", email)
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	tag, err := r.conn(ctx).Exec(ctx,
+		"UPDATE users SET email = $3, version = version + 1 WHERE id = $1 AND version = $2",
+		user.Id.Hex(), user.Version, user.Email,
+	)
+	if err != nil {
+		return r.translateError(ctx, "update user", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return r.missingOrStale(ctx, "update user", user.Id)
+	}
+	return nil
+}
+
+// missingOrStale explains why a conditional update matched no rows.
+func (r *userRepository) missingOrStale(ctx context.Context, operation string, userId primitive.ObjectID) error {
+	var exists bool
+	if err := r.conn(ctx).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userId.Hex()).Scan(&exists); err != nil {
+		return r.translateError(ctx, operation, err)
+	}
+	if !exists {
+		return cadence_errors.ErrNotFound
+	}
+	return cadence_errors.ErrStaleVersion
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	tag, err := r.conn(ctx).Exec(ctx, "DELETE FROM users WHERE id = $1", userId.Hex())
+	if err != nil {
+		return r.translateError(ctx, "delete user", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) getUser(ctx context.Context, operation, query string, arg string) (domain.User, error) {
+	var (
+		id   string
+		user domain.User
+	)
+	if err := r.conn(ctx).QueryRow(ctx, query, arg).Scan(&id, &user.Email, &user.Version); err != nil {
+		return domain.User{}, r.translateError(ctx, operation, err)
+	}
+	var err error
+	if user.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.User{}, r.translateError(ctx, operation, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,36 @@
+package repositories_test
+
+import (
+	"context"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb"
+	"github.com/alexander-littleton/cadence-api/pkg/common/mongodb/mongotest"
+	"github.com/alexander-littleton/cadence-api/pkg/common/postgres/pgtest"
+	"github.com/alexander-littleton/cadence-api/pkg/common/sqlite/sqlitetest"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories/contract"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories/memory"
+	userMongo "github.com/alexander-littleton/cadence-api/pkg/user/repositories/mongo"
+	userPostgres "github.com/alexander-littleton/cadence-api/pkg/user/repositories/postgres"
+	userSQLite "github.com/alexander-littleton/cadence-api/pkg/user/repositories/sqlite"
+	. "github.com/onsi/gomega"
+)
+
+var _ = contract.UserRepository("memory", memory.NewUserRepository)
+
+var _ = contract.UserRepository("mongo", func() repositories.UserRepository {
+	db := mongotest.Database()
+	// email uniqueness is enforced by the database rather than the repository
+	_, err := mongodb.NewMigrator(db, mongodb.Migrations).Up(context.TODO())
+	Expect(err).To(BeNil())
+	return userMongo.NewUserRepository(db.Collection("users"), logging.Discard())
+})
+
+var _ = contract.UserRepository("postgres", func() repositories.UserRepository {
+	return userPostgres.NewUserRepository(pgtest.Database(dsn), logging.Discard())
+})
+
+var _ = contract.UserRepository("sqlite", func() repositories.UserRepository {
+	return userSQLite.NewUserRepository(sqlitetest.Database(), logging.Discard())
+})
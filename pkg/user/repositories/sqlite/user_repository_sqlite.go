@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	commonSQLite "github.com/alexander-littleton/cadence-api/pkg/common/sqlite"
+	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
+	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type userRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewUserRepository stores users in the users table of an SQLite database, keeping ids as hex object ids like the
+// postgres repository.
+func NewUserRepository(db *sql.DB, logger *slog.Logger) repositories.UserRepository {
+	return &userRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// conn returns the transaction ctx is part of, if any, so that the repository can take part in units of work.
+func (r *userRepository) conn(ctx context.Context) commonSQLite.Queryer {
+	return commonSQLite.Conn(ctx, r.db)
+}
+
+func (r *userRepository) translateError(ctx context.Context, operation string, err error) error {
+	return commonSQLite.TranslateAndLog(ctx, r.logger, "users", operation, err)
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user domain.User) error {
+	_, err := r.conn(ctx).ExecContext(ctx, "INSERT INTO users (id, email, version) VALUES (?, ?, ?)",
+		user.Id.Hex(), user.Email, user.Version,
+	)
+	if err != nil {
+		return r.translateError(ctx, "insert user", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error) {
+	return r.getUser(ctx, "find user by id", "SELECT id, email, version FROM users WHERE id = ?", userId.Hex())
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
+	return r.getUser(ctx, "find user by email", "SELECT id, email, version FROM users WHERE email = ?", email)
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	result, err := r.conn(ctx).ExecContext(ctx,
+		"UPDATE users SET email = ?, version = version + 1 WHERE id = ? AND version = ?",
+		user.Email, user.Id.Hex(), user.Version,
+	)
+	if err != nil {
+		return r.translateError(ctx, "update user", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return r.translateError(ctx, "update user", err)
+	} else if affected == 0 {
+		return r.missingOrStale(ctx, "update user", user.Id)
+	}
+	return nil
+}
+
+// missingOrStale explains why a conditional update matched no rows.
+func (r *userRepository) missingOrStale(ctx context.Context, operation string, userId primitive.ObjectID) error {
+	var exists bool
+	if err := r.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)", userId.Hex()).Scan(&exists); err != nil {
+		return r.translateError(ctx, operation, err)
+	}
+	if !exists {
+		return cadence_errors.ErrNotFound
+	}
+	return cadence_errors.ErrStaleVersion
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, userId primitive.ObjectID) error {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM users WHERE id = ?", userId.Hex())
+	if err != nil {
+		return r.translateError(ctx, "delete user", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return r.translateError(ctx, "delete user", err)
+	} else if affected == 0 {
+		return cadence_errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) getUser(ctx context.Context, operation, query string, arg string) (domain.User, error) {
+	var (
+		id   string
+		user domain.User
+	)
+	if err := r.conn(ctx).QueryRowContext(ctx, query, arg).Scan(&id, &user.Email, &user.Version); err != nil {
+		return domain.User{}, r.translateError(ctx, operation, err)
+	}
+	var err error
+	if user.Id, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.User{}, r.translateError(ctx, operation, err)
+	}
+	return user, nil
+}
@@ -6,9 +6,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// UserRepository persists users. Implementations must translate storage specific failures into cadence_errors kinds,
+// returning a NotFound error when no user matches and a Conflict error when a unique constraint is violated.
+//
 //go:generate mockgen --source=user_repository.go --destination=mocks/mock_dependencies.go --package=mocks
 type UserRepository interface {
 	CreateUser(ctx context.Context, user domain.User) error
 	GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (domain.User, error)
+	// UpdateUser replaces the stored user if its version is still user.Version, storing the user with the version
+	// incremented. It returns cadence_errors.ErrStaleVersion when the stored version differs.
+	UpdateUser(ctx context.Context, user domain.User) error
+	DeleteUser(ctx context.Context, userId primitive.ObjectID) error
 }
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/mail"
 
 	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/metrics"
+	"github.com/alexander-littleton/cadence-api/pkg/common/tracing"
+	"github.com/alexander-littleton/cadence-api/pkg/common/transaction"
 	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	"github.com/alexander-littleton/cadence-api/pkg/user/repositories"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,44 +21,73 @@ type Service interface {
 	CreateUser(ctx context.Context, user domain.User) (domain.User, error)
 	GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (domain.User, error)
+	UpdateUser(ctx context.Context, user domain.User) (domain.User, error)
+	DeleteUser(ctx context.Context, userId primitive.ObjectID) error
 }
 
+// OwnedRecords deletes the records a user owns, such as their habits. It is called with the context of the
+// transaction that deletes the user.
+type OwnedRecords func(ctx context.Context, userId primitive.ObjectID) error
+
 type service struct {
 	userRepository repositories.UserRepository
+	transactions   transaction.Runner
+	owned          []OwnedRecords
+	logger         *slog.Logger
+	metrics        *metrics.Metrics
 }
 
-func New(userRepo repositories.UserRepository) Service {
+// New creates the user service. owned lists what else to delete when a user is deleted; the user service cannot
+// depend on the services that own those records, as they depend on it.
+func New(
+	userRepo repositories.UserRepository,
+	transactions transaction.Runner,
+	logger *slog.Logger,
+	metrics *metrics.Metrics,
+	owned ...OwnedRecords,
+) Service {
 	return &service{
 		userRepository: userRepo,
+		transactions:   transactions,
+		owned:          owned,
+		logger:         logger,
+		metrics:        metrics,
 	}
 }
 
-func (r *service) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
+func (r *service) CreateUser(ctx context.Context, user domain.User) (_ domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service/CreateUser")
+	defer func() { tracing.End(span, err) }()
+
 	validatedUser, err := r.validateNewUser(ctx, user)
 	if err != nil {
 		return domain.User{}, err
 	}
 
 	validatedUser.Id = primitive.NewObjectID()
+	validatedUser.Version = 1
 
 	err = r.userRepository.CreateUser(ctx, validatedUser)
 	if err != nil {
 		return domain.User{}, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	r.metrics.UserCreated()
+	r.logger.InfoContext(ctx, "user created", slog.String("created_user_id", validatedUser.Id.Hex()))
 	return validatedUser, nil
 }
 
 func (r *service) validateNewUser(ctx context.Context, user domain.User) (domain.User, error) {
 	if !user.Id.IsZero() {
-		return domain.User{}, fmt.Errorf("%w: %s", cadence_errors.ValidationErr, "expected a user without an id")
+		return domain.User{}, cadence_errors.New(cadence_errors.Validation, "user.id_not_allowed", "expected a user without an id")
 	}
 
 	_, err := r.GetUserByEmail(ctx, user.Email)
 	if err != nil && !errors.Is(err, cadence_errors.ErrNotFound) {
 		return domain.User{}, fmt.Errorf("%s: %w", "failed to get user by email", err)
 	} else if err == nil {
-		return domain.User{}, fmt.Errorf("%w: %s", cadence_errors.ValidationErr, "user with email already exists")
+		return domain.User{}, cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "user with email already exists").
+			WithDetail("email", user.Email)
 	}
 
 	//validator causes a panic during testing, so I'm disabling it for now
@@ -66,9 +99,12 @@ func (r *service) validateNewUser(ctx context.Context, user domain.User) (domain
 	return user, nil
 }
 
-func (r *service) GetUserById(ctx context.Context, userId primitive.ObjectID) (domain.User, error) {
+func (r *service) GetUserById(ctx context.Context, userId primitive.ObjectID) (_ domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service/GetUserById")
+	defer func() { tracing.End(span, err) }()
+
 	if userId.IsZero() {
-		return domain.User{}, fmt.Errorf("%w: %s", cadence_errors.ValidationErr, "valid user id must be provided")
+		return domain.User{}, cadence_errors.New(cadence_errors.Validation, "user.id_required", "valid user id must be provided")
 	}
 	user, err := r.userRepository.GetUserById(ctx, userId)
 	if err != nil {
@@ -79,9 +115,13 @@ func (r *service) GetUserById(ctx context.Context, userId primitive.ObjectID) (d
 
 // GetUserByEmail takes an email, validates it, then returns the user with matching email. If a user does not exist in
 // the db, then it will return an error.
-func (r *service) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
-	if _, err := mail.ParseAddress(email); err != nil {
-		return domain.User{}, fmt.Errorf("%w: %s", cadence_errors.ValidationErr, err.Error())
+func (r *service) GetUserByEmail(ctx context.Context, email string) (_ domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service/GetUserByEmail")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = mail.ParseAddress(email); err != nil {
+		return domain.User{}, cadence_errors.Wrap(err, cadence_errors.Validation, "user.email_invalid", "invalid email").
+			WithField("email", "must be a valid email address")
 	}
 
 	user, err := r.userRepository.GetUserByEmail(ctx, email)
@@ -90,3 +130,68 @@ func (r *service) GetUserByEmail(ctx context.Context, email string) (domain.User
 	}
 	return user, nil
 }
+
+// UpdateUser changes the user's email. A non-zero user.Version must match the stored version, otherwise the update is
+// based on the version read here. Either way, an update that lands in between makes this one fail with
+// cadence_errors.ErrStaleVersion rather than overwrite it.
+func (r *service) UpdateUser(ctx context.Context, user domain.User) (_ domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Service/UpdateUser")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err = mail.ParseAddress(user.Email); err != nil {
+		return domain.User{}, cadence_errors.Wrap(err, cadence_errors.Validation, "user.email_invalid", "invalid email").
+			WithField("email", "must be a valid email address")
+	}
+	current, err := r.GetUserById(ctx, user.Id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.Version != 0 && user.Version != current.Version {
+		return domain.User{}, cadence_errors.ErrStaleVersion.WithDetail("version", current.Version)
+	}
+
+	if user.Email != current.Email {
+		existing, err := r.userRepository.GetUserByEmail(ctx, user.Email)
+		if err != nil && !errors.Is(err, cadence_errors.ErrNotFound) {
+			return domain.User{}, fmt.Errorf("%s: %w", "failed to get user by email", err)
+		} else if err == nil && existing.Id != current.Id {
+			return domain.User{}, cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "user with email already exists").
+				WithDetail("email", user.Email)
+		}
+	}
+
+	current.Email = user.Email
+	if err = r.userRepository.UpdateUser(ctx, current); err != nil {
+		return domain.User{}, fmt.Errorf("failed to update user with id %s: %w", current.Id.Hex(), err)
+	}
+	current.Version++
+
+	r.logger.InfoContext(ctx, "user updated")
+	return current, nil
+}
+
+// DeleteUser deletes the user and everything they own in a single transaction, so that a failure part way through
+// leaves nothing deleted.
+func (r *service) DeleteUser(ctx context.Context, userId primitive.ObjectID) (err error) {
+	ctx, span := tracing.Start(ctx, "user.Service/DeleteUser")
+	defer func() { tracing.End(span, err) }()
+
+	if userId.IsZero() {
+		return cadence_errors.New(cadence_errors.Validation, "user.id_required", "valid user id must be provided")
+	}
+	err = r.transactions.Run(ctx, func(ctx context.Context) error {
+		// the user goes last so that, without transactions, a failed delete can be retried
+		for _, deleteOwned := range r.owned {
+			if err := deleteOwned(ctx, userId); err != nil {
+				return err
+			}
+		}
+		return r.userRepository.DeleteUser(ctx, userId)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user with id %s: %w", userId.Hex(), err)
+	}
+
+	r.logger.InfoContext(ctx, "user deleted")
+	return nil
+}
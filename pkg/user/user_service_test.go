@@ -9,26 +9,46 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/alexander-littleton/cadence-api/pkg/common/cadence_errors"
+	"github.com/alexander-littleton/cadence-api/pkg/common/logging"
+	mockTransaction "github.com/alexander-littleton/cadence-api/pkg/common/transaction/mocks"
 	"github.com/alexander-littleton/cadence-api/pkg/user"
 	"github.com/alexander-littleton/cadence-api/pkg/user/domain"
 	mockRepo "github.com/alexander-littleton/cadence-api/pkg/user/repositories/mocks"
 )
 
+// inTransaction marks the contexts handed out by the mock transaction runner.
+type inTransaction struct{}
+
 var _ = Describe("Main", func() {
 	var (
-		ctrl     *gomock.Controller
-		userRepo *mockRepo.MockUserRepository
-		target   user.Service
-		ctx      context.Context
+		ctrl         *gomock.Controller
+		userRepo     *mockRepo.MockUserRepository
+		transactions *mockTransaction.MockRunner
+		ownedDeleted []context.Context
+		ownedErr     error
+		target       user.Service
+		ctx          context.Context
+		recorder     *tracetest.SpanRecorder
 	)
 
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		userRepo = mockRepo.NewMockUserRepository(ctrl)
-		target = user.New(userRepo)
+		transactions = mockTransaction.NewMockRunner(ctrl)
+		ownedDeleted, ownedErr = nil, nil
+		deleteOwned := func(ctx context.Context, userId primitive.ObjectID) error {
+			ownedDeleted = append(ownedDeleted, ctx)
+			return ownedErr
+		}
+		target = user.New(userRepo, transactions, logging.Discard(), nil, deleteOwned)
 		ctx = context.TODO()
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
 	})
 
 	Context("createUser", func() {
@@ -55,6 +75,16 @@ var _ = Describe("Main", func() {
 				Expect(err).To(BeNil())
 				Expect(createdUser.Email).To(Equal(user.Email))
 			})
+			It("traces the email lookup within the create span", func() {
+				spans := map[string]sdktrace.ReadOnlySpan{}
+				for _, span := range recorder.Ended() {
+					spans[span.Name()] = span
+				}
+				Expect(spans).To(HaveKey("user.Service/CreateUser"))
+				Expect(spans).To(HaveKey("user.Service/GetUserByEmail"))
+				Expect(spans["user.Service/GetUserByEmail"].Parent().SpanID()).
+					To(Equal(spans["user.Service/CreateUser"].SpanContext().SpanID()))
+			})
 		})
 		Context("the user already has an object id", func() {
 			BeforeEach(func() {
@@ -68,7 +98,7 @@ var _ = Describe("Main", func() {
 		})
 		Context("failed to get existing user with matching email", func() {
 			BeforeEach(func() {
-				userRepo.EXPECT().GetUserByEmail(ctx, user.Email).Return(domain.User{}, errors.New("boom"))
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(domain.User{}, errors.New("boom"))
 			})
 			It("returns an error", func() {
 				Expect(err).To(Not(BeNil()))
@@ -78,17 +108,17 @@ var _ = Describe("Main", func() {
 		})
 		Context("a user with matching email already exists", func() {
 			BeforeEach(func() {
-				userRepo.EXPECT().GetUserByEmail(ctx, user.Email).Return(domain.User{Email: user.Email}, nil)
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(domain.User{Email: user.Email}, nil)
 			})
-			It("returns a validation Err", func() {
+			It("returns a conflict Err", func() {
 				Expect(err).To(Not(BeNil()))
-				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+				Expect(errors.Is(err, cadence_errors.ErrConflict)).To(BeTrue())
 				Expect(createdUser).To(Equal(domain.User{}))
 			})
 		})
 		Context("the repository layer returns an error", func() {
 			BeforeEach(func() {
-				userRepo.EXPECT().GetUserByEmail(ctx, user.Email).Return(domain.User{}, cadence_errors.ErrNotFound)
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(domain.User{}, cadence_errors.ErrNotFound)
 			})
 			//It("returns a validation error", func() {
 			//	Expect(err).To(Not(BeNil()))
@@ -98,8 +128,8 @@ var _ = Describe("Main", func() {
 		})
 		Context("the repository layer returns an error", func() {
 			BeforeEach(func() {
-				userRepo.EXPECT().GetUserByEmail(ctx, user.Email).Return(domain.User{}, cadence_errors.ErrNotFound)
-				userRepo.EXPECT().CreateUser(ctx, mock.MatchedBy(func(u domain.User) bool {
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(domain.User{}, cadence_errors.ErrNotFound)
+				userRepo.EXPECT().CreateUser(gomock.Any(), mock.MatchedBy(func(u domain.User) bool {
 					return u.Email == user.Email
 				})).Return(errors.New("boom"))
 			})
@@ -110,6 +140,117 @@ var _ = Describe("Main", func() {
 			})
 		})
 	})
+	Context("UpdateUser", func() {
+		var (
+			stored  domain.User
+			update  domain.User
+			updated domain.User
+			err     error
+		)
+		BeforeEach(func() {
+			stored = domain.User{Id: primitive.NewObjectID(), Email: "old@test.com", Version: 3}
+			update = domain.User{Id: stored.Id, Email: "new@test.com"}
+		})
+		JustBeforeEach(func() {
+			updated, err = target.UpdateUser(ctx, update)
+		})
+		Context("the new email is free", func() {
+			BeforeEach(func() {
+				userRepo.EXPECT().GetUserById(gomock.Any(), stored.Id).Return(stored, nil)
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), update.Email).Return(domain.User{}, cadence_errors.ErrNotFound)
+				userRepo.EXPECT().UpdateUser(gomock.Any(), domain.User{Id: stored.Id, Email: update.Email, Version: 3}).Return(nil)
+			})
+			It("returns the user at its next version", func() {
+				Expect(err).To(BeNil())
+				Expect(updated).To(Equal(domain.User{Id: stored.Id, Email: update.Email, Version: 4}))
+			})
+		})
+		Context("the new email belongs to another user", func() {
+			BeforeEach(func() {
+				userRepo.EXPECT().GetUserById(gomock.Any(), stored.Id).Return(stored, nil)
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), update.Email).Return(domain.User{Id: primitive.NewObjectID()}, nil)
+			})
+			It("returns a conflict error", func() {
+				Expect(err).To(MatchError(cadence_errors.New(cadence_errors.Conflict, "user.email_taken", "")))
+			})
+		})
+		Context("the expected version is not the stored one", func() {
+			BeforeEach(func() {
+				update.Version = 2
+				userRepo.EXPECT().GetUserById(gomock.Any(), stored.Id).Return(stored, nil)
+			})
+			It("returns a stale version error", func() {
+				Expect(err).To(MatchError(cadence_errors.ErrStaleVersion))
+			})
+		})
+		Context("the email is invalid", func() {
+			BeforeEach(func() {
+				update.Email = "nope"
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+			})
+		})
+	})
+	Context("DeleteUser", func() {
+		var (
+			userId primitive.ObjectID
+			err    error
+		)
+		BeforeEach(func() {
+			userId = primitive.NewObjectID()
+		})
+		JustBeforeEach(func() {
+			err = target.DeleteUser(ctx, userId)
+		})
+		Context("the user exists", func() {
+			BeforeEach(func() {
+				transactions.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(context.WithValue(ctx, inTransaction{}, true))
+					})
+				userRepo.EXPECT().DeleteUser(gomock.Any(), userId).DoAndReturn(
+					func(ctx context.Context, _ primitive.ObjectID) error {
+						Expect(ctx.Value(inTransaction{})).To(BeTrue())
+						return nil
+					})
+			})
+			It("deletes the user and what they own in one transaction", func() {
+				Expect(err).To(BeNil())
+				Expect(ownedDeleted).To(HaveLen(1))
+				Expect(ownedDeleted[0].Value(inTransaction{})).To(BeTrue())
+			})
+		})
+		Context("the user does not exist", func() {
+			BeforeEach(func() {
+				transactions.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+				userRepo.EXPECT().DeleteUser(gomock.Any(), userId).Return(cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(errors.Is(err, cadence_errors.ErrNotFound)).To(BeTrue())
+			})
+		})
+		Context("deleting what the user owns fails", func() {
+			BeforeEach(func() {
+				ownedErr = errors.New("boom")
+				transactions.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+			})
+			It("keeps the user and returns the error", func() {
+				Expect(err).To(MatchError(ownedErr))
+			})
+		})
+		Context("userId is zero", func() {
+			BeforeEach(func() {
+				userId = primitive.NilObjectID
+			})
+			It("returns a validation error", func() {
+				Expect(errors.Is(err, cadence_errors.ValidationErr)).To(BeTrue())
+				Expect(ownedDeleted).To(BeEmpty())
+			})
+		})
+	})
 	Context("GetUserById", func() {
 		var (
 			userId       primitive.ObjectID
@@ -124,7 +265,7 @@ var _ = Describe("Main", func() {
 			BeforeEach(func() {
 				userId = primitive.NewObjectID()
 				expectedUser = domain.User{Id: userId, Email: "test@test.com"}
-				userRepo.EXPECT().GetUserById(ctx, userId).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserById(gomock.Any(), userId).Return(expectedUser, nil)
 			})
 			It("returns a valid user", func() {
 				Expect(err).To(BeNil())
@@ -157,7 +298,7 @@ var _ = Describe("Main", func() {
 			BeforeEach(func() {
 				email = "test@test.com"
 				expectedUser = domain.User{Id: primitive.NewObjectID(), Email: email}
-				userRepo.EXPECT().GetUserByEmail(ctx, email).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), email).Return(expectedUser, nil)
 			})
 			It("returns a user", func() {
 				Expect(err).To(BeNil())
@@ -178,7 +319,7 @@ var _ = Describe("Main", func() {
 			BeforeEach(func() {
 				email = "test@test.com"
 				expectedUser = domain.User{Id: primitive.NewObjectID(), Email: email}
-				userRepo.EXPECT().GetUserByEmail(ctx, email).Return(domain.User{}, errors.New("boom"))
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), email).Return(domain.User{}, errors.New("boom"))
 			})
 			It("returns an error", func() {
 				Expect(err).To(Not(BeNil()))
@@ -186,5 +327,15 @@ var _ = Describe("Main", func() {
 				Expect(user).To(Equal(domain.User{}))
 			})
 		})
+		Context("the user does not exist", func() {
+			BeforeEach(func() {
+				email = "test@test.com"
+				userRepo.EXPECT().GetUserByEmail(gomock.Any(), email).Return(domain.User{}, cadence_errors.ErrNotFound)
+			})
+			It("returns a not found error", func() {
+				Expect(cadence_errors.KindOf(err)).To(Equal(cadence_errors.NotFound))
+				Expect(user).To(Equal(domain.User{}))
+			})
+		})
 	})
 })